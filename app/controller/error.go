@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/opensaucerer/barf"
+	"github.com/opensaucerer/barf/app/global"
+)
+
+// Errors maps the errors returned by the logic layer to the status and code they are answered with
+func Errors(err error) *barf.Error {
+
+	mappings := []struct {
+		target error
+		status int
+		code   string
+	}{
+		{global.ErrInvalidUser, http.StatusBadRequest, "invalid_user"},
+		{global.ErrInvalidAccountNumber, http.StatusBadRequest, "invalid_account_number"},
+		{global.ErrInvalidSessionId, http.StatusBadRequest, "invalid_session_id"},
+		{global.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+		{global.ErrAccountNotFound, http.StatusNotFound, "account_not_found"},
+		{global.ErrTransactionNotFound, http.StatusNotFound, "transaction_not_found"},
+		{global.ErrEmailTaken, http.StatusConflict, "email_taken"},
+		{global.ErrInsufficientBalance, http.StatusUnprocessableEntity, "insufficient_funds"},
+		{global.ErrInsufficientLockedBalance, http.StatusUnprocessableEntity, "insufficient_locked_funds"},
+	}
+	for _, m := range mappings {
+		if errors.Is(err, m.target) {
+			return &barf.Error{Status: m.status, Code: m.code, Message: err.Error()}
+		}
+	}
+
+	// the remaining errors of the logic layer are written for users, i.e "we are having issues processing your deposit"
+	return &barf.Error{Status: http.StatusInternalServerError, Code: "internal_error", Message: err.Error()}
+}
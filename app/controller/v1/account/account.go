@@ -2,6 +2,7 @@ package account
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/opensaucerer/barf"
 	accountl "github.com/opensaucerer/barf/app/logic/v1/account"
@@ -12,21 +13,17 @@ import (
 
 func Create(w http.ResponseWriter, r *http.Request) {
 
-	var data userr.User
+	var data struct {
+		Key string `json:"key" validate:"required"`
+	}
 	if err := barf.Request(r).Body().Format(&data); err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
 		return
 	}
 
-	account, err := accountl.Create(&data)
+	account, err := accountl.Create(&userr.User{Key: data.Key})
 	if err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Error(err)
 		return
 	}
 
@@ -41,23 +38,18 @@ func Search(w http.ResponseWriter, r *http.Request) {
 
 	var data accountr.Account
 	if err := barf.Request(r).Query().Format(&data); err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
 		return
 	}
 
 	account, err := accountl.Search(data.Number)
 	if err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Error(err)
 		return
 	}
 
-	barf.Response(w).Status(http.StatusOK).JSON(barf.Res{
+	// tellers holding the current version of the account get a 304
+	barf.Response(w).Status(http.StatusOK).ETag(version(account)).LastModified(account.UpdatedAt).JSON(barf.Res{
 		Status:  true,
 		Data:    account,
 		Message: "account retrieved",
@@ -68,23 +60,21 @@ func Deposit(w http.ResponseWriter, r *http.Request) {
 
 	var data transaction.Transaction
 	if err := barf.Request(r).Body().Format(&data); err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
+		return
+	}
+
+	if !precondition(w, r, data.Number) {
 		return
 	}
 
 	tx, err := accountl.Deposit(&data)
 	if err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Error(err)
 		return
 	}
 
-	barf.Response(w).Status(http.StatusOK).JSON(barf.Res{
+	barf.Response(w).Status(http.StatusOK).ETag(version(&tx.Account)).JSON(barf.Res{
 		Status:  true,
 		Data:    tx,
 		Message: "deposit successful",
@@ -95,23 +85,21 @@ func Lock(w http.ResponseWriter, r *http.Request) {
 
 	var data transaction.Transaction
 	if err := barf.Request(r).Body().Format(&data); err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
+		return
+	}
+
+	if !precondition(w, r, data.Number) {
 		return
 	}
 
 	tx, err := accountl.Lock(&data)
 	if err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Error(err)
 		return
 	}
 
-	barf.Response(w).Status(http.StatusOK).JSON(barf.Res{
+	barf.Response(w).Status(http.StatusOK).ETag(version(&tx.Account)).JSON(barf.Res{
 		Status:  true,
 		Data:    tx,
 		Message: "money locked",
@@ -122,23 +110,21 @@ func Unlock(w http.ResponseWriter, r *http.Request) {
 
 	var data transaction.Transaction
 	if err := barf.Request(r).Body().Format(&data); err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
+		return
+	}
+
+	if !precondition(w, r, data.Number) {
 		return
 	}
 
 	tx, err := accountl.Unlock(&data)
 	if err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Error(err)
 		return
 	}
 
-	barf.Response(w).Status(http.StatusOK).JSON(barf.Res{
+	barf.Response(w).Status(http.StatusOK).ETag(version(&tx.Account)).JSON(barf.Res{
 		Status:  true,
 		Data:    tx,
 		Message: "money unlocked",
@@ -149,23 +135,21 @@ func Withdraw(w http.ResponseWriter, r *http.Request) {
 
 	var data transaction.Transaction
 	if err := barf.Request(r).Body().Format(&data); err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
+		return
+	}
+
+	if !precondition(w, r, data.Number) {
 		return
 	}
 
 	tx, err := accountl.Withdraw(&data)
 	if err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Error(err)
 		return
 	}
 
-	barf.Response(w).Status(http.StatusOK).JSON(barf.Res{
+	barf.Response(w).Status(http.StatusOK).ETag(version(&tx.Account)).JSON(barf.Res{
 		Status:  true,
 		Data:    tx,
 		Message: "withdrawal processed",
@@ -176,25 +160,146 @@ func Transactions(w http.ResponseWriter, r *http.Request) {
 
 	var data accountr.Account
 	if err := barf.Request(r).Query().Format(&data); err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
 		return
 	}
 
 	txs, err := accountl.Transactions(data.Number)
 	if err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Error(err)
 		return
 	}
 
-	barf.Response(w).Status(http.StatusOK).JSON(barf.Res{
+	// tellers can export the transactions as CSV with Accept: text/csv
+	barf.Response(w).Status(http.StatusOK).Send(barf.Res{
 		Status:  true,
 		Data:    txs,
 		Message: "transactions retrieved",
 	})
 }
+
+// Feed streams the transactions recorded on an account as server-sent events
+func Feed(w http.ResponseWriter, r *http.Request) {
+
+	var data accountr.Account
+	if err := barf.Request(r).Query().Format(&data); err != nil {
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
+		return
+	}
+
+	if _, err := accountl.Search(data.Number); err != nil {
+		barf.Response(w).Error(err)
+		return
+	}
+
+	// subscribe before catching up such that no transaction falls in between
+	txs, unsubscribe := accountl.Subscribe(data.Number)
+	defer unsubscribe()
+
+	stream, err := barf.Response(w).Stream()
+	if err != nil {
+		barf.Response(w).Error(err)
+		return
+	}
+
+	// catch up a reconnecting teller with the transactions they missed
+	if last := stream.LastEventID(); last != "" {
+		// a failed catch up leaves the teller with the live transactions only
+		missed, _ := accountl.Missed(data.Number, last)
+		for _, tx := range missed {
+			if stream.Send(barf.Event{ID: tx.SessionId, Event: "transaction", Data: tx}) != nil {
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Done():
+			return
+		case tx := <-txs:
+			if stream.Send(barf.Event{ID: tx.SessionId, Event: "transaction", Data: tx}) != nil {
+				return
+			}
+		}
+	}
+}
+
+// Stream pushes the transactions recorded on an account over a WebSocket connection
+func Stream(conn *barf.Conn) {
+
+	var data accountr.Account
+	if err := barf.Request(conn.Request()).Query().Format(&data); err != nil {
+		conn.WriteJSON(barf.Res{
+			Status:  false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := accountl.Search(data.Number); err != nil {
+		conn.WriteJSON(barf.Res{
+			Status:  false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	txs, unsubscribe := accountl.Subscribe(data.Number)
+	defer unsubscribe()
+
+	// tellers have nothing to say but reading answers their pings and notices when they leave
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-conn.Done():
+			return
+		case tx := <-txs:
+			if err := conn.WriteJSON(barf.Res{
+				Status:  true,
+				Data:    tx,
+				Message: "transaction recorded",
+			}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// version returns the entity tag of the current state of an account, which changes with every transaction
+func version(account *accountr.Account) string {
+	return barf.StrongETag([]byte(account.Number + account.UpdatedAt.UTC().Format(time.RFC3339Nano)))
+}
+
+// precondition answers with a 412 and returns false when the If-Match or If-Unmodified-Since header of the request
+// does not match the current state of the account, such that tellers do not act on an outdated balance
+func precondition(w http.ResponseWriter, r *http.Request, number string) bool {
+
+	if r.Header.Get("If-Match") == "" && r.Header.Get("If-Unmodified-Since") == "" {
+		return true
+	}
+
+	account, err := accountl.Search(number)
+	if err != nil {
+		barf.Response(w).Error(err)
+		return false
+	}
+
+	if barf.Request(r).Preconditions(version(account), account.UpdatedAt) != 0 {
+		barf.Response(w).Error(&barf.Error{
+			Status:  http.StatusPreconditionFailed,
+			Code:    "account_modified",
+			Message: "account was modified since it was retrieved",
+		})
+		return false
+	}
+
+	return true
+}
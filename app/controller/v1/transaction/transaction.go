@@ -12,19 +12,13 @@ func Transaction(w http.ResponseWriter, r *http.Request) {
 
 	var data transactionr.Transaction
 	if err := barf.Request(r).Query().Format(&data); err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
 		return
 	}
 
 	tx, err := transactionl.Transaction(data.SessionId)
 	if err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Error(err)
 		return
 	}
 
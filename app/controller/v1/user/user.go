@@ -12,19 +12,13 @@ func Register(w http.ResponseWriter, r *http.Request) {
 
 	var data userr.User
 	if err := barf.Request(r).Body().Format(&data); err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
 		return
 	}
 
 	user, err := userl.Register(&data)
 	if err != nil {
-		barf.Response(w).Status(http.StatusBadRequest).JSON(barf.Res{
-			Status:  false,
-			Message: err.Error(),
-		})
+		barf.Response(w).Error(err)
 		return
 	}
 
@@ -0,0 +1,19 @@
+package global
+
+import "errors"
+
+// errors returned by the logic layer, which the http layer maps to a status with controller.Errors
+var (
+	ErrInvalidUser          = errors.New("please provide a valid user")
+	ErrInvalidAccountNumber = errors.New("please provide a valid account number")
+	ErrInvalidSessionId     = errors.New("please provide a valid session id")
+
+	ErrUserNotFound        = errors.New("user not found")
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrTransactionNotFound = errors.New("transaction not found")
+
+	ErrEmailTaken = errors.New("a user with this email address already exists")
+
+	ErrInsufficientBalance       = errors.New("insufficient funds in account's available balance")
+	ErrInsufficientLockedBalance = errors.New("insufficient funds in account's locked balance")
+)
@@ -1,9 +1,5 @@
 package global
 
-const (
-	MinAge = 7
-)
-
 var (
 	FactoryPointer int64 = 0
 	FactoryCursor  int64 = 0
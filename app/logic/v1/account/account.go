@@ -17,16 +17,16 @@ import (
 // Create adds a new account for the given user.
 func Create(user *userr.User) (*accountr.Account, error) {
 
-	// again, this is not good enough and should be improved with a validation middleware
+	// the controller validates the request body, only an empty key is guarded against here
 	if user.Key == "" {
-		return nil, errors.New("please provide a valid user")
+		return nil, global.ErrInvalidUser
 	}
 
 	// validate user's existence
 	user.FindByKey()
 
 	if user.Email == "" {
-		return nil, errors.New("user not found")
+		return nil, global.ErrUserNotFound
 	}
 
 	number, err := repository.GenerateAccountNumber()
@@ -54,7 +54,7 @@ func Create(user *userr.User) (*accountr.Account, error) {
 func Search(number string) (*accountr.Account, error) {
 
 	if number == "" {
-		return nil, errors.New("please provide a valid account number")
+		return nil, global.ErrInvalidAccountNumber
 	}
 
 	account := accountr.Account{
@@ -66,7 +66,7 @@ func Search(number string) (*accountr.Account, error) {
 	}
 
 	if account.User.Key == "" {
-		return nil, errors.New("account not found")
+		return nil, global.ErrAccountNotFound
 	}
 
 	return &account, nil
@@ -76,7 +76,7 @@ func Search(number string) (*accountr.Account, error) {
 func Deposit(tx *transaction.Transaction) (*transaction.Transaction, error) {
 
 	if tx.Number == "" {
-		return nil, errors.New("please provide a valid account number")
+		return nil, global.ErrInvalidAccountNumber
 	}
 
 	// find account
@@ -87,7 +87,7 @@ func Deposit(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	}
 
 	if tx.Account.User.Key == "" {
-		return nil, errors.New("account not found")
+		return nil, global.ErrAccountNotFound
 	}
 
 	// prepare the deposit transaction
@@ -120,6 +120,9 @@ func Deposit(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	// dispose of the transaction
 	database.PostgreSQLDBTx = nil
 
+	// let tellers following the account know
+	publish(tx)
+
 	return tx, nil
 }
 
@@ -127,7 +130,7 @@ func Deposit(tx *transaction.Transaction) (*transaction.Transaction, error) {
 func Lock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 
 	if tx.Number == "" {
-		return nil, errors.New("please provide a valid account number")
+		return nil, global.ErrInvalidAccountNumber
 	}
 
 	// find account
@@ -138,7 +141,7 @@ func Lock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	}
 
 	if tx.Account.User.Key == "" {
-		return nil, errors.New("account not found")
+		return nil, global.ErrAccountNotFound
 	}
 
 	// prepare the lock transaction
@@ -154,7 +157,7 @@ func Lock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	if err := tx.Account.Lock(tx.Amount); err != nil {
 		database.PostgreSQLDBTx.Rollback(context.Background())
 		if err == pgx.ErrNoRows {
-			return nil, errors.New("insufficient funds in account's available balance")
+			return nil, global.ErrInsufficientBalance
 		}
 		return nil, errors.New("we are having issues processing your lock. Please try again later")
 	}
@@ -174,6 +177,9 @@ func Lock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	// dispose of the transaction
 	database.PostgreSQLDBTx = nil
 
+	// let tellers following the account know
+	publish(tx)
+
 	return tx, nil
 }
 
@@ -181,7 +187,7 @@ func Lock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 func Unlock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 
 	if tx.Number == "" {
-		return nil, errors.New("please provide a valid account number")
+		return nil, global.ErrInvalidAccountNumber
 	}
 
 	// find account
@@ -192,7 +198,7 @@ func Unlock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	}
 
 	if tx.Account.User.Key == "" {
-		return nil, errors.New("account not found")
+		return nil, global.ErrAccountNotFound
 	}
 
 	// prepare the unlock transaction
@@ -208,7 +214,7 @@ func Unlock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	if err := tx.Account.Unlock(tx.Amount); err != nil {
 		database.PostgreSQLDBTx.Rollback(context.Background())
 		if err == pgx.ErrNoRows {
-			return nil, errors.New("insufficient funds in account's locked balance")
+			return nil, global.ErrInsufficientLockedBalance
 		}
 		return nil, errors.New("we are having issues processing your unlock. Please try again later")
 	}
@@ -228,6 +234,9 @@ func Unlock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	// dispose of the transaction
 	database.PostgreSQLDBTx = nil
 
+	// let tellers following the account know
+	publish(tx)
+
 	return tx, nil
 }
 
@@ -235,7 +244,7 @@ func Unlock(tx *transaction.Transaction) (*transaction.Transaction, error) {
 func Withdraw(tx *transaction.Transaction) (*transaction.Transaction, error) {
 
 	if tx.Number == "" {
-		return nil, errors.New("please provide a valid account number")
+		return nil, global.ErrInvalidAccountNumber
 	}
 
 	// find account
@@ -246,7 +255,7 @@ func Withdraw(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	}
 
 	if tx.Account.User.Key == "" {
-		return nil, errors.New("account not found")
+		return nil, global.ErrAccountNotFound
 	}
 
 	// prepare the withdraw transaction
@@ -262,7 +271,7 @@ func Withdraw(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	if err := tx.Account.Withdraw(tx.Amount); err != nil {
 		database.PostgreSQLDBTx.Rollback(context.Background())
 		if err == pgx.ErrNoRows {
-			return nil, errors.New("insufficient funds in account's available balance")
+			return nil, global.ErrInsufficientBalance
 		}
 		return nil, errors.New("we are having issues processing your withdraw. Please try again later")
 	}
@@ -282,6 +291,9 @@ func Withdraw(tx *transaction.Transaction) (*transaction.Transaction, error) {
 	// dispose of the transaction
 	database.PostgreSQLDBTx = nil
 
+	// let tellers following the account know
+	publish(tx)
+
 	return tx, nil
 }
 
@@ -289,7 +301,7 @@ func Withdraw(tx *transaction.Transaction) (*transaction.Transaction, error) {
 func Transactions(number string) (transaction.Transactions, error) {
 
 	if number == "" {
-		return nil, errors.New("please provide a valid account number")
+		return nil, global.ErrInvalidAccountNumber
 	}
 
 	// find account
@@ -300,7 +312,7 @@ func Transactions(number string) (transaction.Transactions, error) {
 	}
 
 	if account.User.Key == "" {
-		return nil, errors.New("account not found")
+		return nil, global.ErrAccountNotFound
 	}
 
 	// find transactions
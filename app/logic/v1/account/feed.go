@@ -0,0 +1,74 @@
+package account
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/opensaucerer/barf/app/repository/v1/transaction"
+)
+
+// feed holds the subscribers to the transactions of every account, keyed by account number.
+// Subscribers only hear of transactions processed by this instance of the app.
+var feed = struct {
+	sync.Mutex
+	subscribers map[string]map[chan transaction.Transaction]struct{}
+}{subscribers: map[string]map[chan transaction.Transaction]struct{}{}}
+
+// Subscribe returns a channel receiving the transactions recorded on the given account from now on,
+// along with a function that unsubscribes from them. The channel is closed once unsubscribed.
+func Subscribe(number string) (<-chan transaction.Transaction, func()) {
+	ch := make(chan transaction.Transaction, 16)
+
+	feed.Lock()
+	if feed.subscribers[number] == nil {
+		feed.subscribers[number] = map[chan transaction.Transaction]struct{}{}
+	}
+	feed.subscribers[number][ch] = struct{}{}
+	feed.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			feed.Lock()
+			delete(feed.subscribers[number], ch)
+			if len(feed.subscribers[number]) == 0 {
+				delete(feed.subscribers, number)
+			}
+			feed.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publish sends the given transaction to the subscribers of its account.
+// Slow subscribers miss the transaction rather than hold up the request that recorded it.
+func publish(tx *transaction.Transaction) {
+	feed.Lock()
+	defer feed.Unlock()
+	for ch := range feed.subscribers[tx.Number] {
+		select {
+		case ch <- *tx:
+		default:
+		}
+	}
+}
+
+// Missed returns the transactions recorded on the given account after the one with the given session id,
+// such as those missed by a teller reconnecting to the feed. None are returned for an unknown session id.
+func Missed(number, session string) (transaction.Transactions, error) {
+
+	txs, err := Transactions(number)
+	if err != nil {
+		return nil, err
+	}
+
+	// transactions are recorded in the order of their ids
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].Id < txs[j].Id })
+	for i, tx := range txs {
+		if tx.SessionId == session {
+			return txs[i+1:], nil
+		}
+	}
+
+	return transaction.Transactions{}, nil
+}
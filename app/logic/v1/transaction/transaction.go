@@ -3,6 +3,7 @@ package transaction
 import (
 	"errors"
 
+	"github.com/opensaucerer/barf/app/global"
 	"github.com/opensaucerer/barf/app/repository/v1/transaction"
 )
 
@@ -10,7 +11,7 @@ import (
 func Transaction(sessionId string) (*transaction.Transaction, error) {
 
 	if sessionId == "" {
-		return nil, errors.New("please provide a valid session id")
+		return nil, global.ErrInvalidSessionId
 	}
 
 	// find account
@@ -21,7 +22,7 @@ func Transaction(sessionId string) (*transaction.Transaction, error) {
 	}
 
 	if tx.Number == "" {
-		return nil, errors.New("transaction not found")
+		return nil, global.ErrTransactionNotFound
 	}
 
 	return tx, nil
@@ -10,7 +10,7 @@ import (
 // Register registers a new user
 func Register(user *userr.User) (*userr.User, error) {
 
-	// this is not good enough, we can improve this with a validation middleware. Ideally, I would create one from scratch as most validators have used in golang are just not sufficient for me. I have a plan to work on one here https://github.com/opensaucerer/vibranium
+	// check the validate tags of the user and normalize its fields
 	if err := user.Validate(); err != nil {
 		return nil, err
 	}
@@ -21,7 +21,7 @@ func Register(user *userr.User) (*userr.User, error) {
 	}
 
 	if user.Key != "" {
-		return nil, errors.New("a user with this email address already exists")
+		return nil, global.ErrEmailTaken
 	}
 
 	user.Role = global.Customer
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/opensaucerer/barf"
+	"github.com/opensaucerer/barf/app/controller"
 	"github.com/opensaucerer/barf/app/database"
 	"github.com/opensaucerer/barf/app/global"
 	"github.com/opensaucerer/barf/app/version"
@@ -21,9 +22,11 @@ func main() {
 	// configure barf
 	allow := true
 	if err := barf.Stark(barf.Augment{
-		Port:     global.ENV.Port,
-		Logging:  &allow, // enable request logging
-		Recovery: &allow, // enable panic recovery
+		Port:         global.ENV.Port,
+		Logging:      &allow,              // enable request logging
+		Recovery:     &allow,              // enable panic recovery
+		Compression:  &barf.Compression{}, // compress large transaction histories
+		ErrorHandler: controller.Errors,   // map the errors of the logic layer
 		CORS: &barf.CORS{
 			AllowedOrigins: []string{"https://*.onrender.com"},
 			MaxAge:         3600,
@@ -45,7 +48,7 @@ func main() {
 
 			// a custom security feature. only allow requests containing a valid app token in the header key "zeina-mfi"
 			// this can probably be improved by using asymmetric encryption
-			if r.Header.Get("zeina-mfi") != global.ENV.AppToken {
+			if barf.Request(r).Header().Get("zeina-mfi") != global.ENV.AppToken {
 				barf.Response(w).Status(http.StatusUnauthorized).JSON(nil)
 				return
 			}
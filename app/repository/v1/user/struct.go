@@ -9,10 +9,10 @@ import (
 type User struct {
 	Id        int64       `json:"-"`
 	Key       string      `json:"key"`
-	FirstName string      `json:"first_name"`
-	LastName  string      `json:"last_name"`
-	Email     string      `json:"email"`
-	Age       int         `json:"age"`
+	FirstName string      `json:"first_name" validate:"required"`
+	LastName  string      `json:"last_name" validate:"required"`
+	Email     string      `json:"email" validate:"required,email"`
+	Age       int         `json:"age" validate:"min=7"`
 	Role      global.Role `json:"role"`
 	Active    bool        `json:"active"`
 	CreatedAt time.Time   `json:"created_at"`
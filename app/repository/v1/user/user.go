@@ -3,7 +3,6 @@ package user
 import (
 	"context"
 	"crypto/sha256"
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,23 +10,14 @@ import (
 
 	"github.com/jackc/pgx/v4"
 	"github.com/opensaucerer/barf/app/database"
-	"github.com/opensaucerer/barf/app/global"
 	"github.com/opensaucerer/barf/app/reflection"
+	"github.com/opensaucerer/barf/validate"
 )
 
-// Validate validates the user struct
+// Validate validates the user struct against its validate tags and normalizes its fields
 func (u *User) Validate() error {
-	if u.FirstName == "" {
-		return errors.New("first name is required")
-	}
-	if u.LastName == "" {
-		return errors.New("last name is required")
-	}
-	if u.Email == "" {
-		return errors.New("email is required")
-	}
-	if u.Age < global.MinAge {
-		return fmt.Errorf("age must be greater than %d", global.MinAge-1)
+	if err := validate.Struct(u); err != nil {
+		return err
 	}
 	u.Key = ""
 	u.Email = strings.ToLower(u.Email)
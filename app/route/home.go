@@ -7,5 +7,5 @@ import (
 
 func RegisterHomeRoutes() {
 
-	barf.Get("/", controller.Home)
+	barf.Get("/", controller.Home).Named("home")
 }
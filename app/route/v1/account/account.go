@@ -6,11 +6,14 @@ import (
 )
 
 func RegisterAccountRoutes() {
-	barf.Post("/v1/account/create", accountc.Create)
-	barf.Get("/v1/account/search", accountc.Search)
-	barf.Patch("/v1/account/deposit", accountc.Deposit)
-	barf.Patch("/v1/account/lock", accountc.Lock)
-	barf.Patch("/v1/account/unlock", accountc.Unlock)
-	barf.Patch("/v1/account/withdraw", accountc.Withdraw)
-	barf.Get("/v1/account/transactions", accountc.Transactions)
+	account := barf.RetroFrame("/v1/account")
+	account.Post("/create", accountc.Create).Named("account.create")
+	account.Get("/search", accountc.Search).Named("account.search")
+	account.Patch("/deposit", accountc.Deposit).Named("account.deposit")
+	account.Patch("/lock", accountc.Lock).Named("account.lock")
+	account.Patch("/unlock", accountc.Unlock).Named("account.unlock")
+	account.Patch("/withdraw", accountc.Withdraw).Named("account.withdraw")
+	account.Get("/transactions", accountc.Transactions).Named("account.transactions")
+	account.Get("/transactions/feed", accountc.Feed).Named("account.transactions.feed")
+	account.WS("/stream", accountc.Stream).Named("account.stream")
 }
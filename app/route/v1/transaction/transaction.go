@@ -6,5 +6,5 @@ import (
 )
 
 func RegisterTransactionRoutes() {
-	barf.Get("/v1/transaction", transaction.Transaction)
+	barf.Get("/v1/transaction", transaction.Transaction).Named("transaction")
 }
@@ -6,5 +6,5 @@ import (
 )
 
 func RegisterUserRoutes() {
-	barf.Post("/v1/user/register", userc.Register)
+	barf.Post("/v1/user/register", userc.Register).Named("user.register")
 }
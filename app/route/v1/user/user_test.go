@@ -73,9 +73,9 @@ func TestUserRouteIntegration(t *testing.T) {
 
 		handler.ServeHTTP(writer, req)
 
-		if status := writer.Code; status != http.StatusBadRequest {
+		if status := writer.Code; status != http.StatusConflict {
 			t.Errorf("handler returned wrong status code: got %v want %v",
-				status, http.StatusBadRequest)
+				status, http.StatusConflict)
 		}
 
 		// convert response body to struct
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/opensaucerer/barf"
+	"github.com/opensaucerer/barf/app/controller"
 	"github.com/opensaucerer/barf/app/database"
 	"github.com/opensaucerer/barf/app/global"
 )
@@ -21,6 +22,10 @@ func Setup() {
 	database.NewPostgreSQLConnection(global.ENV.PostgreSQLURI, global.ENV.PostgreSQLConnections)
 
 	database.ReadFileAndExecuteQueries(global.ENV.SQLFilePath)
+
+	// controllers called directly answer the errors of the logic layer as the app does
+	quiet := false
+	barf.Stark(barf.Augment{Logging: &quiet, ErrorHandler: controller.Errors})
 }
 
 // Teardown cleans up the application after testing
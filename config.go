@@ -10,3 +10,18 @@ type Augment = typing.Augment
 
 // CORS holds configuration for Cross-Origin Resource Sharing
 type CORS = typing.CORS
+
+// Files holds configuration for serving static files with barf.Static
+type Files = typing.Files
+
+// Versioning holds configuration for API version negotiation with barf.Version
+type Versioning = typing.Versioning
+
+// Uploads holds configuration for receiving multipart file uploads with barf.Request(r).Files
+type Uploads = typing.Uploads
+
+// Storage stores the files received with barf.Request(r).Files
+type Storage = typing.Storage
+
+// Compression holds configuration for compressing responses with gzip or deflate
+type Compression = typing.Compression
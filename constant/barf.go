@@ -25,11 +25,29 @@ const (
 	// request line. It does not limit the size of the request body.
 	MaxHeaderBytes = 1 << 20 // 1 MB
 
+	// MaxBodySize is the maximum number of bytes the server will read from a request body
+	MaxBodySize = 10 << 20 // 10 MB
+
+	// CompressMinSize is the minimum number of bytes of a response body for it to be compressed
+	CompressMinSize = 1 << 10 // 1 KB
+
 	// Port is the port for the server to listen on
 	Port = ":21186"
 
 	// EnvPath is the path to the environment variables file
 	EnvPath = ".env"
+
+	// DebugRoutesPath is the path the route table is served at in debug mode
+	DebugRoutesPath = "/barf/routes"
+
+	// VersionHeader is the header the API version is requested with and announced in
+	VersionHeader = "API-Version"
+
+	// RequestIDHeader is the header the ID of a request is read from and sent back in
+	RequestIDHeader = "X-Request-ID"
+
+	// LogFormat is the format requests are logged in
+	LogFormat = "text"
 )
 
 var (
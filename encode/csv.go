@@ -0,0 +1,134 @@
+package encode
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/opensaucerer/barf/helper"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// timeType is the type of time.Time which is written as RFC 3339 rather than as a struct
+var timeType = reflect.TypeOf(time.Time{})
+
+// marshaler is the encoding.TextMarshaler interface type
+var marshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+/*
+CSV encodes a slice of structs, or a single struct, as CSV with a header row. The data of a typing.Response is encoded in its place.
+
+Columns are named by the `csv` tag of each field, falling back to its `json` tag and then the field name.
+Times are written as RFC 3339, types implementing encoding.TextMarshaler with their MarshalText method, and nested structs, maps and slices are skipped.
+*/
+func CSV(w io.Writer, v interface{}) error {
+	if res, ok := v.(typing.Response); ok {
+		v = res.Data
+	}
+	rv := indirect(reflect.ValueOf(v))
+	rows := []reflect.Value{rv}
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		rows = make([]reflect.Value, rv.Len())
+		for i := range rows {
+			rows[i] = indirect(rv.Index(i))
+		}
+	}
+	var t reflect.Type
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		t = rv.Type().Elem()
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	} else if rv.IsValid() {
+		t = rv.Type()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot encode %T as CSV, expected a struct or a slice of structs", v)
+	}
+	columns, names := []int{}, []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := helper.Name(f, "csv")
+		if !f.IsExported() || name == "" || !flat(f.Type) {
+			continue
+		}
+		columns = append(columns, i)
+		names = append(names, name)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(names); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, c := range columns {
+			record[i] = cell(row.Field(c))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// flat returns true if values of the given type fit in a single CSV cell
+func flat(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || reflect.PtrTo(t).Implements(marshaler) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array, reflect.Func, reflect.Chan, reflect.Interface:
+		return false
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return true
+}
+
+// cell returns the text of a single CSV cell
+func cell(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return ""
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339)
+	}
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(text)
+	}
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	case reflect.Slice:
+		return string(v.Bytes())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// indirect dereferences v until it is not a pointer or interface, returning an invalid value for nil
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
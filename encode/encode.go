@@ -0,0 +1,157 @@
+package encode
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoder writes v to w in the format of the media type it is registered for
+type Encoder func(w io.Writer, v interface{}) error
+
+// entry is an encoder registered for a media type
+type entry struct {
+	media   string
+	encoder Encoder
+}
+
+var (
+	// mu guards registry
+	mu sync.RWMutex
+	// registry holds the encoders in order of preference. The first one is used when any media type is accepted.
+	registry = []entry{
+		{"application/json", JSON},
+		{"application/xml", XML},
+		{"text/csv", CSV},
+		{"application/msgpack", MessagePack},
+	}
+)
+
+// Register adds an encoder for the given media type, or replaces the encoder already registered for it
+func Register(media string, encoder Encoder) {
+	media = strings.ToLower(media)
+	mu.Lock()
+	defer mu.Unlock()
+	for i, e := range registry {
+		if e.media == media {
+			registry[i].encoder = encoder
+			return
+		}
+	}
+	registry = append(registry, entry{media, encoder})
+}
+
+// accepted is a media range of an Accept header
+type accepted struct {
+	media string
+	q     float64
+	// specificity is 0 for */*, 1 for type/* and 2 for type/subtype
+	specificity int
+}
+
+/*
+Negotiate returns the media type and encoder of the registered encoder best matching the given Accept header.
+Media ranges are ranked by quality, then by specificity and then by their order in the header. An empty header accepts anything.
+
+It returns false if none of the accepted media types has an encoder.
+*/
+func Negotiate(accept string) (string, Encoder, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if strings.TrimSpace(accept) == "" {
+		return registry[0].media, registry[0].encoder, true
+	}
+	ranges := parse(accept)
+	for _, r := range ranges {
+		if r.q <= 0 {
+			break
+		}
+		for _, e := range registry {
+			if matches(r.media, e.media) && !excluded(ranges, e.media) {
+				return e.media, e.encoder, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// parse returns the media ranges of the given Accept header sorted by quality and specificity, keeping the order of the header otherwise
+func parse(accept string) []accepted {
+	var ranges []accepted
+	for _, part := range strings.Split(accept, ",") {
+		media, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		specificity := 2
+		switch {
+		case media == "*/*":
+			specificity = 0
+		case strings.HasSuffix(media, "/*"):
+			specificity = 1
+		}
+		ranges = append(ranges, accepted{media: media, q: q, specificity: specificity})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return ranges[i].specificity > ranges[j].specificity
+	})
+	return ranges
+}
+
+// matches returns true if the given media range covers the media type.
+// Media types with a +json or +xml structured suffix, such as the vendor types API versions are requested with
+// i.e application/vnd.zeina.v2+json, are covered by application/json and application/xml.
+func matches(r, media string) bool {
+	if r == "*/*" || r == media {
+		return true
+	}
+	if strings.HasSuffix(r, "/*") {
+		return strings.HasPrefix(media, strings.TrimSuffix(r, "*"))
+	}
+	switch {
+	case strings.HasSuffix(r, "+json"):
+		return media == "application/json"
+	case strings.HasSuffix(r, "+xml"):
+		return media == "application/xml"
+	}
+	return false
+}
+
+// excluded returns true if the most specific media range covering the media type has a quality of 0 i.e text/csv;q=0
+func excluded(ranges []accepted, media string) bool {
+	best := -1
+	q := 1.0
+	for _, r := range ranges {
+		if matches(r.media, media) && r.specificity > best {
+			best, q = r.specificity, r.q
+		}
+	}
+	return q <= 0
+}
+
+// JSON encodes v with encoding/json
+func JSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// XML encodes v with encoding/xml, preceded by the XML header
+func XML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
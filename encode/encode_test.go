@@ -0,0 +1,134 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// transaction mirrors the shape of a transaction of the zeina app
+type transaction struct {
+	Id        int64     `json:"-"`
+	Number    string    `json:"number"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+	Account   struct{}  `json:"account"`
+	Note      string    `json:"note,omitempty"`
+}
+
+// go test -v -run TestNegotiateUnit ./...
+func TestNegotiateUnit(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		accept string
+		media  string
+	}{
+		{"Should default to JSON without an Accept header", "", "application/json"},
+		{"Should default to JSON when anything is accepted", "*/*", "application/json"},
+		{"Should pick the accepted media type", "text/csv", "text/csv"},
+		{"Should rank media types by quality", "application/json;q=0.5, application/xml", "application/xml"},
+		{"Should rank media types by specificity", "*/*;q=0.8, text/*;q=0.8, application/msgpack;q=0.8", "application/msgpack"},
+		{"Should keep the order of the header otherwise", "text/csv, application/xml", "text/csv"},
+		{"Should match wildcard subtypes", "text/*", "text/csv"},
+		{"Should honour exclusions", "application/json;q=0, */*", "application/xml"},
+		{"Should match structured suffixes of vendor types", "application/vnd.zeina.v2+json", "application/json"},
+		{"Should match XML structured suffixes", "application/vnd.zeina.v2+xml", "application/xml"},
+		{"Should not match unavailable media types", "application/pdf, image/*", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			media, _, ok := Negotiate(c.accept)
+			if ok != (c.media != "") || media != c.media {
+				t.Fatalf("unexpected media type: got %v want %v", media, c.media)
+			}
+
+		})
+	}
+
+	t.Run("Should use registered encoders", func(t *testing.T) {
+
+		Register("text/plain", func(w io.Writer, v interface{}) error {
+			_, err := io.WriteString(w, "plain")
+			return err
+		})
+		media, encoder, ok := Negotiate("text/plain")
+		var buf bytes.Buffer
+		if !ok || media != "text/plain" || encoder(&buf, nil) != nil || buf.String() != "plain" {
+			t.Fatalf("unexpected encoder: got %v %v", media, buf.String())
+		}
+
+	})
+}
+
+// go test -v -run TestEncodeUnit ./...
+func TestEncodeUnit(t *testing.T) {
+
+	created := time.Date(2026, time.October, 1, 9, 30, 0, 0, time.UTC)
+	txs := []transaction{
+		{Number: "0123456789", Amount: 2500.5, CreatedAt: created},
+		{Number: "9876543210", Amount: 100, CreatedAt: created, Note: "a, \"quoted\" note"},
+	}
+
+	t.Run("Should encode slices of structs as CSV", func(t *testing.T) {
+
+		var buf bytes.Buffer
+		if err := CSV(&buf, typing.Response{Status: true, Data: txs}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "number,amount,created_at,note\n" +
+			"0123456789,2500.5,2026-10-01T09:30:00Z,\n" +
+			"9876543210,100,2026-10-01T09:30:00Z,\"a, \"\"quoted\"\" note\"\n"
+		if buf.String() != want {
+			t.Fatalf("unexpected CSV: got %q want %q", buf.String(), want)
+		}
+
+	})
+
+	t.Run("Should not encode values other than structs as CSV", func(t *testing.T) {
+
+		if err := CSV(io.Discard, map[string]string{"a": "b"}); err == nil {
+			t.Fatal("expected error but got none")
+		}
+
+	})
+
+	t.Run("Should encode MessagePack", func(t *testing.T) {
+
+		cases := []struct {
+			value interface{}
+			hex   string
+		}{
+			{nil, "c0"},
+			{true, "c3"},
+			{-1, "ff"},
+			{200, "ccc8"},
+			{-200, "d1ff38"},
+			{1.5, "cb3ff8000000000000"},
+			{"zeina", "a57a65696e61"},
+			{[]int{1, 2}, "920102"},
+			{[]byte{1}, "c40101"},
+			{struct {
+				Number string `json:"number"`
+				Note   string `json:"note,omitempty"`
+				Secret string `json:"-"`
+			}{Number: "01"}, "81a66e756d626572a23031"},
+		}
+		for _, c := range cases {
+			var buf bytes.Buffer
+			if err := MessagePack(&buf, c.value); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hex.EncodeToString(buf.Bytes()); got != c.hex {
+				t.Fatalf("unexpected encoding of %v: got %v want %v", c.value, got, c.hex)
+			}
+		}
+
+	})
+}
@@ -0,0 +1,245 @@
+package encode
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/opensaucerer/barf/helper"
+)
+
+/*
+MessagePack encodes v in the MessagePack binary format.
+
+Structs are encoded as maps keyed by the `msgpack` tag of each field, falling back to its `json` tag and then the field name,
+and fields tagged with omitempty are left out when empty. Times are encoded as RFC 3339 strings and types implementing
+encoding.TextMarshaler with their MarshalText method.
+*/
+func MessagePack(w io.Writer, v interface{}) error {
+	bw := bufio.NewWriter(w)
+	if err := pack(bw, reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// pack writes the MessagePack encoding of v
+func pack(w *bufio.Writer, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return w.WriteByte(0xc0)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return w.WriteByte(0xc0)
+	}
+	if v.Type() == timeType {
+		return str(w, v.Interface().(time.Time).Format(time.RFC3339Nano))
+	}
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			text, err := m.MarshalText()
+			if err != nil {
+				return err
+			}
+			return str(w, string(text))
+		}
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return w.WriteByte(0xc3)
+		}
+		return w.WriteByte(0xc2)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return integer(w, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return unsigned(w, v.Uint())
+	case reflect.Float32:
+		w.WriteByte(0xca)
+		return binary.Write(w, binary.BigEndian, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		w.WriteByte(0xcb)
+		return binary.Write(w, binary.BigEndian, math.Float64bits(v.Float()))
+	case reflect.String:
+		return str(w, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return w.WriteByte(0xc0)
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			return bin(w, v.Bytes())
+		}
+		header(w, v.Len(), 0x90, 0xdc, 0xdd)
+		for i := 0; i < v.Len(); i++ {
+			if err := pack(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.IsNil() {
+			return w.WriteByte(0xc0)
+		}
+		header(w, v.Len(), 0x80, 0xde, 0xdf)
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := pack(w, iter.Key()); err != nil {
+				return err
+			}
+			if err := pack(w, iter.Value()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		fields := fields(v)
+		header(w, len(fields), 0x80, 0xde, 0xdf)
+		for _, f := range fields {
+			str(w, f.name)
+			if err := pack(w, f.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot encode %s as MessagePack", v.Type())
+}
+
+// field is a struct field encoded as a map entry
+type field struct {
+	name  string
+	value reflect.Value
+}
+
+// fields returns the fields of the struct value v that should be encoded, including those of embedded structs
+func fields(v reflect.Value) []field {
+	var out []field
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if _, tagged := f.Tag.Lookup("msgpack"); f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			out = append(out, fields(v.Field(i))...)
+			continue
+		}
+		name := helper.Name(f, "msgpack")
+		if name == "" || (omitempty(f) && v.Field(i).IsZero()) {
+			continue
+		}
+		out = append(out, field{name, v.Field(i)})
+	}
+	return out
+}
+
+// omitempty returns true if the msgpack or json tag of the field has the omitempty option
+func omitempty(f reflect.StructField) bool {
+	tag, ok := f.Tag.Lookup("msgpack")
+	if !ok {
+		tag = f.Tag.Get("json")
+	}
+	for _, option := range strings.Split(tag, ",")[1:] {
+		if option == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
+// header writes the header of a map or array of n entries with the given fix, 16 bit and 32 bit markers
+func header(w *bufio.Writer, n int, fix, m16, m32 byte) {
+	switch {
+	case n < 16:
+		w.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(m16)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(m32)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	}
+}
+
+// str writes a string
+func str(w *bufio.Writer, s string) error {
+	switch n := len(s); {
+	case n < 32:
+		w.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		w.WriteByte(0xd9)
+		w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(0xda)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(0xdb)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	}
+	_, err := w.WriteString(s)
+	return err
+}
+
+// bin writes a byte slice
+func bin(w *bufio.Writer, b []byte) error {
+	switch n := len(b); {
+	case n <= math.MaxUint8:
+		w.WriteByte(0xc4)
+		w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(0xc5)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(0xc6)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// integer writes a signed integer in its most compact form
+func integer(w *bufio.Writer, i int64) error {
+	switch {
+	case i >= 0:
+		return unsigned(w, uint64(i))
+	case i >= -32:
+		return w.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8:
+		w.WriteByte(0xd0)
+		return w.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16:
+		w.WriteByte(0xd1)
+		return binary.Write(w, binary.BigEndian, int16(i))
+	case i >= math.MinInt32:
+		w.WriteByte(0xd2)
+		return binary.Write(w, binary.BigEndian, int32(i))
+	}
+	w.WriteByte(0xd3)
+	return binary.Write(w, binary.BigEndian, i)
+}
+
+// unsigned writes an unsigned integer in its most compact form
+func unsigned(w *bufio.Writer, u uint64) error {
+	switch {
+	case u <= 0x7f:
+		return w.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		w.WriteByte(0xcc)
+		return w.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		w.WriteByte(0xcd)
+		return binary.Write(w, binary.BigEndian, uint16(u))
+	case u <= math.MaxUint32:
+		w.WriteByte(0xce)
+		return binary.Write(w, binary.BigEndian, uint32(u))
+	}
+	w.WriteByte(0xcf)
+	return binary.Write(w, binary.BigEndian, u)
+}
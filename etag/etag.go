@@ -0,0 +1,111 @@
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Strong returns a strong entity tag for the given representation. Strong tags only match byte for byte identical representations.
+func Strong(b []byte) string {
+	sum := sha256.Sum256(b)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:18]) + `"`
+}
+
+// Weak returns a weak entity tag for the given representation. Weak tags also match representations that are equivalent, i.e once compressed.
+func Weak(b []byte) string {
+	return "W/" + Strong(b)
+}
+
+/*
+Check evaluates the preconditions of the given request against the current entity tag and modification time of the resource,
+in the order of RFC 9110 section 13.2.2. It returns 0 when the request should proceed, 304 when the client has the current
+representation already and 412 when a precondition failed. An empty tag or zero time means the resource has none.
+*/
+func Check(r *http.Request, tag string, modified time.Time) int {
+	safe := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	if h := r.Header.Get("If-Match"); h != "" {
+		if !match(h, tag, true) {
+			return http.StatusPreconditionFailed
+		}
+	} else if t, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil && !modified.IsZero() {
+		if modified.Truncate(time.Second).After(t) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if h := r.Header.Get("If-None-Match"); h != "" {
+		if match(h, tag, false) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if t, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && safe && !modified.IsZero() {
+		if !modified.Truncate(time.Second).After(t) {
+			return http.StatusNotModified
+		}
+	}
+
+	return 0
+}
+
+// match reports whether the given If-Match or If-None-Match header matches the tag, with the strong or weak comparison of RFC 9110 section 8.8.3.2
+func match(header, tag string, strong bool) bool {
+	if tag == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range list(header) {
+		if strong {
+			if !weak(candidate) && !weak(tag) && candidate == tag {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// weak reports whether the given tag is weak
+func weak(tag string) bool {
+	return strings.HasPrefix(tag, "W/")
+}
+
+// list splits a list of entity tags, keeping commas within quoted tags
+func list(header string) []string {
+	var tags []string
+	for header != "" {
+		header = strings.TrimLeft(header, " \t,")
+		if header == "" {
+			break
+		}
+		prefix := ""
+		if strings.HasPrefix(header, "W/") {
+			prefix, header = "W/", header[2:]
+		}
+		if !strings.HasPrefix(header, `"`) {
+			// not a valid tag, skip to the next one
+			if i := strings.IndexByte(header, ','); i >= 0 {
+				header = header[i+1:]
+				continue
+			}
+			break
+		}
+		end := strings.IndexByte(header[1:], '"')
+		if end < 0 {
+			break
+		}
+		tags = append(tags, prefix+header[:end+2])
+		header = header[end+2:]
+	}
+	return tags
+}
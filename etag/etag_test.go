@@ -0,0 +1,71 @@
+package etag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// go test -v -run TestETagUnit ./...
+func TestETagUnit(t *testing.T) {
+
+	t.Run("Should compute stable strong and weak tags", func(t *testing.T) {
+
+		tag := Strong([]byte(`{"number":"0123456789"}`))
+		if tag != Strong([]byte(`{"number":"0123456789"}`)) || tag == Strong([]byte(`{"number":"9876543210"}`)) {
+			t.Fatalf("unexpected tag: got %v", tag)
+		}
+		if tag[0] != '"' || tag[len(tag)-1] != '"' || Weak([]byte(`{"number":"0123456789"}`)) != "W/"+tag {
+			t.Fatalf("unexpected tag format: got %v", tag)
+		}
+
+	})
+
+	t.Run("Should split lists of tags", func(t *testing.T) {
+
+		tags := list(`"a", W/"b,c" ,"d"`)
+		if len(tags) != 3 || tags[0] != `"a"` || tags[1] != `W/"b,c"` || tags[2] != `"d"` {
+			t.Fatalf("unexpected tags: got %v", tags)
+		}
+
+	})
+
+	modified := time.Date(2026, time.October, 1, 9, 30, 0, 0, time.UTC)
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+
+	cases := []struct {
+		name   string
+		method string
+		header http.Header
+		tag    string
+		status int
+	}{
+		{"Should proceed without preconditions", http.MethodGet, http.Header{}, `"v1"`, 0},
+		{"Should answer 304 for a matching If-None-Match", http.MethodGet, http.Header{"If-None-Match": {`"v0", W/"v1"`}}, `"v1"`, http.StatusNotModified},
+		{"Should proceed for a stale If-None-Match", http.MethodGet, http.Header{"If-None-Match": {`"v0"`}}, `"v1"`, 0},
+		{"Should answer 412 for a matching If-None-Match on mutations", http.MethodPatch, http.Header{"If-None-Match": {"*"}}, `"v1"`, http.StatusPreconditionFailed},
+		{"Should proceed for a matching If-Match", http.MethodPatch, http.Header{"If-Match": {`"v1"`}}, `"v1"`, 0},
+		{"Should answer 412 for a stale If-Match", http.MethodPatch, http.Header{"If-Match": {`"v0"`}}, `"v1"`, http.StatusPreconditionFailed},
+		{"Should compare If-Match strongly", http.MethodPatch, http.Header{"If-Match": {`W/"v1"`}}, `W/"v1"`, http.StatusPreconditionFailed},
+		{"Should answer 412 for If-Match without a current representation", http.MethodPatch, http.Header{"If-Match": {"*"}}, "", http.StatusPreconditionFailed},
+		{"Should answer 304 when not modified since", http.MethodGet, http.Header{"If-Modified-Since": {after}}, "", http.StatusNotModified},
+		{"Should proceed when modified since", http.MethodGet, http.Header{"If-Modified-Since": {before}}, "", 0},
+		{"Should ignore If-Modified-Since along with If-None-Match", http.MethodGet, http.Header{"If-None-Match": {`"v0"`}, "If-Modified-Since": {after}}, `"v1"`, 0},
+		{"Should answer 412 when modified since If-Unmodified-Since", http.MethodPatch, http.Header{"If-Unmodified-Since": {before}}, "", http.StatusPreconditionFailed},
+		{"Should proceed when unmodified since", http.MethodPatch, http.Header{"If-Unmodified-Since": {after}}, "", 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			r := httptest.NewRequest(c.method, "/v1/account/search?number=0123456789", nil)
+			r.Header = c.header
+			if got := Check(r, c.tag, modified); got != c.status {
+				t.Fatalf("unexpected status: got %v want %v", got, c.status)
+			}
+
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package helper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+/*
+Bind assigns the given values to the fields of the struct v points to. Fields are named by the tag with the given key,
+falling back to their json tag and then the field name. Slice fields receive every value of their name while other fields receive the first.
+Embedded structs are bound as if their fields belonged to v.
+
+A default value is assigned to fields without a value with the default option of the tag. The default values of a slice field are separated by |.
+
+	Limit int      `query:"limit,default=20"`
+	Types []string `query:"type,default=credit|debit"`
+
+It returns typing.FieldErrors listing every value that could not be converted into the type of its field.
+*/
+func Bind(v interface{}, values map[string][]string, key string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot bind into %T, expected a pointer to a struct", v)
+	}
+	errs := bind(rv.Elem(), values, key, typing.FieldErrors{})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bind assigns the given values to the fields of the struct value rv and returns errs along with the fields that could not be assigned
+func bind(rv reflect.Value, values map[string][]string, key string, errs typing.FieldErrors) typing.FieldErrors {
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, tagged := f.Tag.Lookup(key)
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			errs = bind(rv.Field(i), values, key, errs)
+			continue
+		}
+		name := Name(f, key)
+		if name == "" {
+			continue
+		}
+		value, ok := values[name]
+		if !ok || len(value) == 0 {
+			def, found := option(tag, "default")
+			if !found {
+				continue
+			}
+			value = strings.Split(def, "|")
+		}
+		if err := set(rv.Field(i), value); err != nil {
+			errs = append(errs, typing.FieldError{Field: name, Message: fmt.Sprintf("invalid %s: %s", name, err)})
+		}
+	}
+	return errs
+}
+
+// option returns the value of the option with the given name in a tag such as limit,default=20
+func option(tag, name string) (string, bool) {
+	parts := strings.Split(tag, ",")
+	for _, part := range parts[1:] {
+		if strings.HasPrefix(part, name+"=") {
+			return strings.TrimPrefix(part, name+"="), true
+		}
+	}
+	return "", false
+}
+
+// set assigns the given values to field, converting each of them for a slice field and the first for any other field
+func set(field reflect.Value, values []string) error {
+	if field.Kind() != reflect.Slice || field.Type().Elem().Kind() == reflect.Uint8 {
+		return Assign(field, values[0])
+	}
+	// types such as net.IP are slices but unmarshal themselves from a single value
+	if field.CanAddr() && unmarshaler(field) {
+		return Assign(field, values[0])
+	}
+	slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+	for i, value := range values {
+		if err := Assign(slice.Index(i), value); err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+	return nil
+}
@@ -0,0 +1,103 @@
+package helper
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Name returns the name of a struct field as given by the tag with the given key, falling back to its json tag and then to the field name.
+// It returns an empty string if the field should be skipped.
+func Name(f reflect.StructField, key string) string {
+	for _, k := range []string{key, "json"} {
+		tag, ok := f.Tag.Lookup(k)
+		if !ok {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// layouts are the layouts a time.Time is parsed with, in order
+var layouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+// Assign converts the given string into the type of v and stores it in v which must be settable.
+// A time.Time is parsed as RFC 3339 or as a date such as 2006-01-02.
+// Other types implementing encoding.TextUnmarshaler are converted with their UnmarshalText method and a []byte holds the bytes of s.
+func Assign(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return Assign(v.Elem(), s)
+	}
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not a valid time, expected a date or an RFC 3339 time", s)
+	}
+	if v.CanAddr() && unmarshaler(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", s, v.Type())
+		}
+		v.SetInt(integer)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		integer, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", s, v.Type())
+		}
+		v.SetUint(integer)
+	case reflect.Float32, reflect.Float64:
+		float, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", s, v.Type())
+		}
+		v.SetFloat(float)
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", s, v.Type())
+		}
+		v.SetBool(boolean)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("cannot assign %q to %s", s, v.Type())
+		}
+		v.SetBytes([]byte(s))
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("cannot assign %q to %s", s, v.Type())
+		}
+		v.Set(reflect.ValueOf(s))
+	default:
+		return fmt.Errorf("cannot assign %q to %s", s, v.Type())
+	}
+	return nil
+}
+
+// unmarshaler returns true if a pointer to the addressable value v implements encoding.TextUnmarshaler
+func unmarshaler(v reflect.Value) bool {
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
@@ -0,0 +1,23 @@
+package helper
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Vary adds the given header names to the Vary header unless they are already listed
+func Vary(h http.Header, names ...string) {
+	for _, name := range names {
+		listed := false
+		for _, line := range h.Values("Vary") {
+			for _, v := range strings.Split(line, ",") {
+				if v = strings.TrimSpace(v); v == "*" || strings.EqualFold(v, name) {
+					listed = true
+				}
+			}
+		}
+		if !listed {
+			h.Add("Vary", name)
+		}
+	}
+}
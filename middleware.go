@@ -3,8 +3,8 @@ package barf
 import "github.com/opensaucerer/barf/server"
 
 /*
-Hippocampus prepares the given barf router or base barf handler for hijacking. To take over the base barf handler, omit the router argument.
+Hippocampus prepares the given barf router, barf app or base barf handler for hijacking. To take over the base barf handler, omit the router argument.
 
-Note: the base barf handler is the one that is created by the barf.Stark() function and can only be hijacked before the barf.Beck() function is called.
+Note: the base barf handler is the one that is created by the barf.Stark() function and can only be hijacked before the barf.Beck() function is called. The same applies to the handler of an app created by barf.New() and its app.Beck() method.
 */
 var Hippocampus = server.Hippocampus
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Augment is a middleware that makes the given config and its parsed trusted proxies available through the request context
+// such that barf.Request(r) can honour them. It also wraps the response writer such that barf.Response(w) can reach the request.
+func Augment(aug *typing.Augment, proxies []*net.IPNet) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), typing.AugmentCtxKey{}, aug)
+			r = r.WithContext(context.WithValue(ctx, typing.ProxiesCtxKey{}, proxies))
+			h.ServeHTTP(NewWriter(w, r), r)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/opensaucerer/barf/router/body"
+)
+
+// Body is a middleware that limits the request body to the given number of bytes and caches it once read such that it can be read again.
+// Requests declaring a larger body are answered with a 413 before reaching the next handler. A negative limit disables the limit.
+func Body(limit int64, respond func(w http.ResponseWriter, status bool, statusCode int, message string, data map[string]interface{})) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limit >= 0 && r.ContentLength > limit {
+				respond(w, false, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds the limit of %d bytes", limit), nil)
+				return
+			}
+			h.ServeHTTP(w, body.Cache(r, limit))
+		})
+	}
+}
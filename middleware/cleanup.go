@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Cleanup is a middleware that runs the functions registered while serving a request, such as the removal of uploaded files, once it is served
+func Cleanup(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cleanup := &[]func(){}
+		defer func() {
+			for _, f := range *cleanup {
+				f()
+			}
+		}()
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), typing.CleanupCtxKey{}, cleanup)))
+	})
+}
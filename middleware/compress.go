@@ -0,0 +1,318 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/opensaucerer/barf/constant"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// compressible holds the content types compressed by default
+var compressible = []string{"text/*", "application/json", "application/xml", "application/javascript", "image/svg+xml", "*+json", "*+xml"}
+
+// pools holds the pooled gzip and deflate writers, keyed by encoding and level
+var pools sync.Map
+
+// encoder is a compressing writer that can be reset to write to another writer
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+// pool returns the pool of writers for the given encoding and level
+func pool(encoding string, level int) *sync.Pool {
+	key := encoding + strconv.Itoa(level)
+	if p, ok := pools.Load(key); ok {
+		return p.(*sync.Pool)
+	}
+	p, _ := pools.LoadOrStore(key, &sync.Pool{New: func() interface{} {
+		// the level is validated before the pool is created
+		if encoding == "gzip" {
+			w, _ := gzip.NewWriterLevel(io.Discard, level)
+			return w
+		}
+		w, _ := flate.NewWriter(io.Discard, level)
+		return w
+	}})
+	return p.(*sync.Pool)
+}
+
+// Compress is a middleware that compresses responses with gzip or deflate, whichever the request accepts best
+func Compress(c typing.Compression) func(h http.Handler) http.Handler {
+	level := c.Level
+	if level == 0 || level < flate.HuffmanOnly || level > flate.BestCompression {
+		level = flate.DefaultCompression
+	}
+	min := c.MinSize
+	if min == 0 {
+		min = constant.CompressMinSize
+	}
+	types := c.ContentTypes
+	if len(types) == 0 {
+		types = compressible
+	}
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := acceptEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, level: level, min: min, types: types, head: r.Method == http.MethodHead}
+			defer func() {
+				// the response of an unrecovered panic is cut short, finishing the compressed body would hide it
+				if err := recover(); err != nil {
+					panic(err)
+				}
+				cw.close()
+			}()
+			h.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// acceptEncoding returns the best of gzip and deflate accepted by the given Accept-Encoding header, or "" for neither
+func acceptEncoding(header string) string {
+	best, quality := "", 0.0
+	wildcard := -1.0
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if f, err := strconv.ParseFloat(params[2:], 64); err == nil {
+				q = f
+			}
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		qualities[name] = q
+	}
+	// gzip is preferred over deflate when both are accepted equally
+	for _, encoding := range []string{"gzip", "deflate"} {
+		q, ok := qualities[encoding]
+		if !ok {
+			q = wildcard
+		}
+		if q > quality {
+			best, quality = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response to decide whether it is worth compressing
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	level    int
+	min      int
+	types    []string
+	// code is the status code the handler wrote, if any
+	code int
+	buf  []byte
+	// decided is true once the header was written, compressed or not
+	decided  bool
+	encoder  encoder
+	hijacked bool
+	// head is true for HEAD requests, whose body is only measured to send the same header as the GET request
+	head bool
+}
+
+// WriteHeader holds on to the status code until the response is known to be compressed or not
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.code != 0 {
+		return
+	}
+	// informational responses are sent as they are
+	if code >= 100 && code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.code = code
+}
+
+// Write buffers the body until it is large enough to be compressed
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < w.min {
+			return len(p), nil
+		}
+		w.decide(true)
+		if err := w.drain(); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(p)
+	}
+	return w.body().Write(p)
+}
+
+// Flush writes the buffered body and compressed bytes to the client, such that streamed responses stay live
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+		w.drain()
+	}
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, i.e for WebSocket connections, if the response has not started
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("barf: %T does not support hijacking", w.ResponseWriter)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped http.ResponseWriter. It is used by http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide writes the header, compressed if the response qualifies. Bodies under the minimum size only qualify when streamed.
+func (w *compressWriter) decide(large bool) {
+	w.decided = true
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	switch {
+	case large && w.qualifies():
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", w.encoding)
+		// the compressed representation is no longer byte for byte the same
+		weaken(h)
+		w.encoder = pool(w.encoding, w.level).Get().(encoder)
+		w.encoder.Reset(w.body())
+	case w.code == http.StatusNotModified:
+		// the client validates the compressed representation it was sent, which carries the weak tag
+		weaken(h)
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+// weaken turns the strong entity tag of the given header, if any, into a weak one
+func weaken(h http.Header) {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+}
+
+// qualifies reports whether the response can be compressed
+func (w *compressWriter) qualifies() bool {
+	switch w.code {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	media, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, t := range w.types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch {
+		case t == media, t == "*/*":
+			return true
+		case strings.HasSuffix(t, "/*") && strings.HasPrefix(media, strings.TrimSuffix(t, "*")):
+			return true
+		case strings.HasPrefix(t, "*+") && strings.HasSuffix(media, t[1:]):
+			return true
+		}
+	}
+	return false
+}
+
+// drain writes the buffered body
+func (w *compressWriter) drain() error {
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(buf)
+	} else {
+		_, err = w.body().Write(buf)
+	}
+	return err
+}
+
+// body returns the writer the response body is sent to, which discards it for HEAD requests
+func (w *compressWriter) body() io.Writer {
+	if w.head {
+		return io.Discard
+	}
+	return w.ResponseWriter
+}
+
+// measured reports whether the body written to the given writer reaches a compressWriter of a HEAD request
+func measured(w http.ResponseWriter) bool {
+	for {
+		switch rw := w.(type) {
+		case *compressWriter:
+			return rw.head
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+// close writes what is left of the response once it is served and returns the encoder to its pool
+func (w *compressWriter) close() {
+	if w.hijacked {
+		return
+	}
+	if !w.decided {
+		// handlers that write nothing still get their status code sent
+		if w.code == 0 && len(w.buf) == 0 {
+			return
+		}
+		w.decide(len(w.buf) >= w.min)
+		w.drain()
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		pool(w.encoding, w.level).Put(w.encoder)
+		w.encoder = nil
+	}
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// go test -v -run TestAcceptEncodingUnit ./...
+func TestAcceptEncodingUnit(t *testing.T) {
+
+	cases := []struct {
+		header   string
+		encoding string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"br, *", "gzip"},
+		{"*;q=0.5, gzip;q=0", "deflate"},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"br, identity", ""},
+	}
+	for _, c := range cases {
+		if got := acceptEncoding(c.header); got != c.encoding {
+			t.Fatalf("unexpected encoding for %q: got %v want %v", c.header, got, c.encoding)
+		}
+	}
+}
+
+// go test -v -run TestCompressUnit ./...
+func TestCompressUnit(t *testing.T) {
+
+	history := `[` + strings.Repeat(`{"number":"0123456789","amount":2500,"type":1},`, 64) + `{}]`
+
+	handler := Compress(typing.Compression{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/account/transactions":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"v1"`)
+			w.WriteHeader(http.StatusOK)
+			io.WriteString(w, history)
+		case "/v1/account/search":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"number":"0123456789"}`)
+		case "/v1/files/passport.png":
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, strings.Repeat("x", 2048))
+		case "/v1/account/transactions/feed":
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "data: first\n\n")
+			w.(http.Flusher).Flush()
+		case "/v1/account/statement":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, history)
+			panic("statement printer jammed")
+		}
+	}))
+
+	serve := func(path, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept-Encoding", accept)
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("Should compress large responses with gzip", func(t *testing.T) {
+
+		w := serve("/v1/account/transactions", "gzip, deflate")
+		if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Fatalf("unexpected headers: got %v", w.Header())
+		}
+		if w.Header().Get("ETag") != `W/"v1"` {
+			t.Fatalf("unexpected etag: got %v want %v", w.Header().Get("ETag"), `W/"v1"`)
+		}
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(zr)
+		if string(body) != history {
+			t.Fatalf("unexpected body: got %q", body)
+		}
+
+	})
+
+	t.Run("Should compress with deflate", func(t *testing.T) {
+
+		w := serve("/v1/account/transactions", "deflate")
+		if w.Header().Get("Content-Encoding") != "deflate" {
+			t.Fatalf("unexpected encoding: got %v want %v", w.Header().Get("Content-Encoding"), "deflate")
+		}
+		body, _ := io.ReadAll(flate.NewReader(w.Body))
+		if string(body) != history {
+			t.Fatalf("unexpected body: got %q", body)
+		}
+
+	})
+
+	t.Run("Should not compress small responses, other content types or requests not accepting it", func(t *testing.T) {
+
+		for _, c := range []struct {
+			path   string
+			accept string
+		}{
+			{"/v1/account/search", "gzip"},
+			{"/v1/files/passport.png", "gzip"},
+			{"/v1/account/transactions", ""},
+		} {
+			w := serve(c.path, c.accept)
+			if w.Header().Get("Content-Encoding") != "" || w.Code != http.StatusOK {
+				t.Fatalf("unexpected compression for %s: got %v", c.path, w.Header())
+			}
+			if c.path == "/v1/account/transactions" && w.Body.String() != history {
+				t.Fatalf("unexpected body: got %q", w.Body.String())
+			}
+		}
+
+	})
+
+	t.Run("Should flush streamed responses", func(t *testing.T) {
+
+		w := serve("/v1/account/transactions/feed", "gzip")
+		if w.Header().Get("Content-Encoding") != "gzip" || !w.Flushed {
+			t.Fatalf("unexpected response: got %v %v", w.Header(), w.Flushed)
+		}
+		zr, _ := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+		body, _ := io.ReadAll(zr)
+		if string(body) != "data: first\n\n" {
+			t.Fatalf("unexpected body: got %q", body)
+		}
+
+	})
+
+	t.Run("Should not finish the compressed body of a response cut short by a panic", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		func() {
+			defer func() {
+				if err := recover(); err != "statement printer jammed" {
+					t.Fatalf("unexpected panic: got %v want %v", err, "statement printer jammed")
+				}
+			}()
+			r := httptest.NewRequest(http.MethodGet, "/v1/account/statement", nil)
+			r.Header.Set("Accept-Encoding", "gzip")
+			handler.ServeHTTP(w, r)
+		}()
+		zr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(zr); err != io.ErrUnexpectedEOF {
+			t.Fatalf("unexpected error: got %v want %v", err, io.ErrUnexpectedEOF)
+		}
+
+	})
+}
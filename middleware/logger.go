@@ -1,33 +1,135 @@
 package middleware
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
 	"net/http"
-	"reflect"
 	"strconv"
 	"time"
-	"unsafe"
 
+	"github.com/opensaucerer/barf/constant"
 	logger "github.com/opensaucerer/barf/log"
+	"github.com/opensaucerer/barf/router/ip"
+	"github.com/opensaucerer/barf/typing"
 )
 
-// Logger logs the request to the console
-func Logger(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// create an addressable copy of the response writer
-		wt := reflect.ValueOf(w).Elem()
-		aw := reflect.New(wt.Type()).Elem()
-		aw.Set(wt)
-		// field 16 of the struct embeded in http.ResponseWriter is the status code
-		uf := aw.Field(16)
-		uf = reflect.NewAt(uf.Type(), unsafe.Pointer(uf.UnsafeAddr())).Elem()
-		// get status code
-		code := uf.Interface().(int)
-		// format: utc timestamp: user-agent - http/version: method - path - status code - status text
-		msg := time.Now().UTC().Format(time.RFC3339) + ": " + r.UserAgent() + " - " + r.Proto + ": " + r.Method + " - " + r.URL.Path + " - " + strconv.Itoa(code) + " - " + http.StatusText(code)
-		// log request
-		logger.Code(msg, code)
-
-		// call next middleware
-		// next.ServeHTTP(w, r)
-	})
+// entry is a request logged in json
+type entry struct {
+	Time      string  `json:"time"`
+	RequestID string  `json:"request_id"`
+	IP        string  `json:"ip"`
+	Proto     string  `json:"proto"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Route     string  `json:"route,omitempty"`
+	Status    int     `json:"status"`
+	Bytes     int64   `json:"bytes"`
+	Duration  float64 `json:"duration_ms"`
+	UserAgent string  `json:"user_agent"`
+}
+
+/*
+Logger logs every request once it is served, one line per request, in the format of the given config.
+
+	2024-01-02T15:04:05Z: 6f1c... - 10.0.0.1 - HTTP/1.1: GET - /v1/account/0123456789 (/v1/account/:number) - 200 - OK - 512B - 1.2ms - curl/8.4.0
+
+Requests are identified by their X-Request-ID header, or a random ID when they come without one, which is sent back in
+the X-Request-ID header of the response and available to handlers with barf.Request(r).ID().
+*/
+func Logger(aug *typing.Augment, trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(constant.RequestIDHeader)
+			if !identifier(id) {
+				id = generate()
+			}
+			w.Header().Set(constant.RequestIDHeader, id)
+			r = r.WithContext(context.WithValue(r.Context(), typing.RequestIDCtxKey{}, id))
+
+			rw := NewWriter(w, r)
+			// log from a deferred function such that requests that panic without being recovered are logged too
+			defer func() {
+				err := recover()
+				if err != nil && rw.status == 0 {
+					// net/http answers unrecovered panics by closing the connection
+					rw.status = http.StatusInternalServerError
+				}
+				write(aug, record(rw, r, id, trusted))
+				if err != nil {
+					panic(err)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// record returns the log entry of the request served through the given writer
+func record(rw *Writer, r *http.Request, id string, trusted []*net.IPNet) entry {
+	return entry{
+		Time:      rw.start.UTC().Format(time.RFC3339),
+		RequestID: id,
+		IP:        ip.Client(r, trusted),
+		Proto:     r.Proto,
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Route:     rw.Route(),
+		Status:    rw.Status(),
+		Bytes:     rw.Written(),
+		Duration:  float64(rw.Duration().Microseconds()) / 1000,
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// write writes the given entry to the log output of the given config
+func write(aug *typing.Augment, e entry) {
+	var line string
+	if aug.LogFormat == "json" {
+		b, _ := json.Marshal(e)
+		line = string(b)
+	} else {
+		route := ""
+		if e.Route != "" && e.Route != e.Path {
+			route = " (" + e.Route + ")"
+		}
+		// format: utc timestamp: request id - client ip - http/version: method - path (route) - status code - status text - bytes - duration - user-agent
+		line = e.Time + ": " + e.RequestID + " - " + e.IP + " - " + e.Proto + ": " + e.Method + " - " + e.Path + route + " - " +
+			strconv.Itoa(e.Status) + " - " + http.StatusText(e.Status) + " - " + strconv.FormatInt(e.Bytes, 10) + "B - " +
+			strconv.FormatFloat(e.Duration, 'f', -1, 64) + "ms - " + e.UserAgent
+		if aug.LogOutput == nil {
+			logger.Code(line, e.Status)
+			return
+		}
+	}
+	out := aug.LogOutput
+	if out == nil {
+		out = log.Writer()
+	}
+	io.WriteString(out, line+"\n")
+}
+
+// identifier returns true if the given request ID sent by the client can be logged as is.
+// It must be at most 128 printable ASCII characters without spaces.
+func identifier(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// generate returns a random request ID
+func generate() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
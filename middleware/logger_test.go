@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opensaucerer/barf/constant"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// go test -v -run TestWriterUnit ./...
+func TestWriterUnit(t *testing.T) {
+
+	t.Run("Should record the status and bytes written", func(t *testing.T) {
+		rw := NewWriter(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		rw.WriteHeader(http.StatusCreated)
+		io.WriteString(rw, "created")
+		if rw.Status() != http.StatusCreated || rw.Written() != 7 {
+			t.Fatalf("unexpected capture: got %v %v want %v %v", rw.Status(), rw.Written(), http.StatusCreated, 7)
+		}
+	})
+
+	t.Run("Should record an implicit 200 and ignore informational status codes", func(t *testing.T) {
+		rw := NewWriter(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		rw.WriteHeader(http.StatusEarlyHints)
+		io.WriteString(rw, "ok")
+		rw.WriteHeader(http.StatusInternalServerError)
+		if rw.Status() != http.StatusOK {
+			t.Fatalf("unexpected status: got %v want %v", rw.Status(), http.StatusOK)
+		}
+	})
+
+	t.Run("Should still flush through the wrapped writer", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		var w http.ResponseWriter = NewWriter(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		w.(http.Flusher).Flush()
+		if !rec.Flushed {
+			t.Fatalf("unexpected flush: got %v want %v", rec.Flushed, true)
+		}
+		if _, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			t.Fatalf("unexpected hijack: got %v want an error", err)
+		}
+	})
+}
+
+// go test -v -run TestLoggerUnit ./...
+func TestLoggerUnit(t *testing.T) {
+
+	var out bytes.Buffer
+	aug := &typing.Augment{LogFormat: "json", LogOutput: &out}
+	handler := Logger(aug, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		annotate(w, "/v1/account/:number")
+		if r.Context().Value(typing.RequestIDCtxKey{}) == nil {
+			t.Fatalf("unexpected request id: got %v want an id", nil)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"status":false}`)
+	}))
+
+	t.Run("Should log one json line once the request is served", func(t *testing.T) {
+		out.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/v1/account/0123456789", nil)
+		req.Header.Set(constant.RequestIDHeader, "teller-42")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Header().Get(constant.RequestIDHeader) != "teller-42" {
+			t.Fatalf("unexpected request id header: got %v want %v", rec.Header().Get(constant.RequestIDHeader), "teller-42")
+		}
+		if strings.Count(out.String(), "\n") != 1 {
+			t.Fatalf("unexpected log: got %q want one line", out.String())
+		}
+		var e entry
+		if err := json.Unmarshal(out.Bytes(), &e); err != nil {
+			t.Fatalf("unexpected error: got %v want %v", err, nil)
+		}
+		want := entry{RequestID: "teller-42", IP: "192.0.2.1", Method: http.MethodGet, Path: "/v1/account/0123456789", Route: "/v1/account/:number", Status: http.StatusNotFound, Bytes: 16}
+		if e.RequestID != want.RequestID || e.IP != want.IP || e.Method != want.Method || e.Path != want.Path || e.Route != want.Route || e.Status != want.Status || e.Bytes != want.Bytes {
+			t.Fatalf("unexpected entry: got %+v want %+v", e, want)
+		}
+	})
+
+	t.Run("Should generate a request id for invalid ones", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(constant.RequestIDHeader, "two words")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if id := rec.Header().Get(constant.RequestIDHeader); len(id) != 32 {
+			t.Fatalf("unexpected request id: got %v want a random id", id)
+		}
+	})
+
+	t.Run("Should log text lines", func(t *testing.T) {
+		out.Reset()
+		aug.LogFormat = "text"
+		defer func() { aug.LogFormat = "json" }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/account/0123456789", nil))
+		if !strings.Contains(out.String(), "GET - /v1/account/0123456789 (/v1/account/:number) - 404 - Not Found - 16B") {
+			t.Fatalf("unexpected log: got %q", out.String())
+		}
+	})
+
+	t.Run("Should log requests that panic without being recovered", func(t *testing.T) {
+		out.Reset()
+		panicking := Logger(aug, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("teller drawer jammed")
+		}))
+
+		func() {
+			defer func() {
+				if err := recover(); err != "teller drawer jammed" {
+					t.Fatalf("unexpected panic: got %v want %v", err, "teller drawer jammed")
+				}
+			}()
+			panicking.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/account/withdraw", nil))
+		}()
+
+		if !strings.Contains(out.String(), `"status":500`) {
+			t.Fatalf("unexpected log: got %q want a 500 line", out.String())
+		}
+	})
+}
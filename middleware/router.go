@@ -1,31 +1,70 @@
 package middleware
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 
+	"github.com/opensaucerer/barf/helper"
 	"github.com/opensaucerer/barf/router"
 
 	"github.com/opensaucerer/barf/typing"
 )
 
-// Router routes requests to the correct handler
-func Router(respond func(w http.ResponseWriter, status bool, statusCode int, message string, data map[string]interface{})) func(next http.Handler) http.Handler {
+// Router routes requests to the correct handler among the routes registered on the given root router
+func Router(respond func(w http.ResponseWriter, status bool, statusCode int, message string, data map[string]interface{}), rt *router.Router) func(next http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// get route function
+			requested := rt.Negotiate(r)
 			route := router.Route{
 				Path:    router.Path(r.URL),
 				Method:  strings.ToLower(r.Method),
 				Handler: nil,
 				Params:  map[string]string{},
+				Router:  rt,
+				Host:    r.Host,
+				Version: requested,
 			}
 			// check if route exists
 			if !route.Exists() {
-				respond(w, false, http.StatusNotFound, fmt.Sprintf("Path /%s for method %s not found", route.Path, strings.ToUpper(route.Method)), nil)
+				allowed := route.Allowed()
+				switch {
+				case len(allowed) == 0:
+					respond(w, false, http.StatusNotFound, fmt.Sprintf("Path /%s for method %s not found", route.Path, strings.ToUpper(route.Method)), nil)
+				case r.Method == http.MethodHead && helper.StringArray(allowed).Contains(http.MethodGet):
+					// serve HEAD requests from the GET handler without a body
+					route.Method = strings.ToLower(http.MethodGet)
+					if !route.Exists() {
+						rt.Announce(w, requested, "")
+						respond(w, false, http.StatusNotAcceptable, fmt.Sprintf("Version %s is not available for path /%s", route.Version, route.Path), nil)
+						break
+					}
+					rt.Announce(w, requested, route.Version)
+					annotate(w, route.Pattern)
+					ctx := context.WithValue(r.Context(), typing.ParamsCtxKey{}, route.Params)
+					route.Handler(&head{ResponseWriter: w, measured: measured(w)}, r.WithContext(ctx))
+				case r.Method == http.MethodOptions:
+					w.Header().Set("Allow", strings.Join(allowed, ", "))
+					w.WriteHeader(http.StatusNoContent)
+				case helper.StringArray(allowed).Contains(strings.ToUpper(route.Method)):
+					// the route exists but not for the requested version
+					rt.Announce(w, requested, "")
+					respond(w, false, http.StatusNotAcceptable, fmt.Sprintf("Version %s is not available for path /%s", route.Version, route.Path), nil)
+				default:
+					w.Header().Set("Allow", strings.Join(allowed, ", "))
+					respond(w, false, http.StatusMethodNotAllowed, fmt.Sprintf("Method %s not allowed for path /%s", strings.ToUpper(route.Method), route.Path), nil)
+				}
 			} else {
+				// announce the version serving the request, if any
+				rt.Announce(w, requested, route.Version)
+
+				// let the logger know the route serving the request
+				annotate(w, route.Pattern)
+
 				// load params into context if any
 				ctx := context.WithValue(r.Context(), typing.ParamsCtxKey{}, route.Params)
 
@@ -37,3 +76,53 @@ func Router(respond func(w http.ResponseWriter, status bool, statusCode int, mes
 		})
 	}
 }
+
+// head is a response writer that discards the response body as required for HEAD requests
+type head struct {
+	http.ResponseWriter
+	// wrote is true once the header is written
+	wrote bool
+	// measured is true if the body is passed on to be measured, and discarded, by the compressing writer
+	measured bool
+}
+
+// WriteHeader writes the header of the response
+func (h *head) WriteHeader(code int) {
+	h.wrote = true
+	h.ResponseWriter.WriteHeader(code)
+}
+
+// Write reports the body as written without sending it to the client.
+// The Content-Type is still sniffed from the first write, as it is for the GET request.
+func (h *head) Write(b []byte) (int, error) {
+	if h.measured {
+		return h.ResponseWriter.Write(b)
+	}
+	if !h.wrote {
+		if _, ok := h.Header()["Content-Type"]; !ok && len(b) > 0 {
+			h.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		h.WriteHeader(http.StatusOK)
+	}
+	return len(b), nil
+}
+
+// Unwrap returns the wrapped http.ResponseWriter. It is used by http.ResponseController.
+func (h *head) Unwrap() http.ResponseWriter {
+	return h.ResponseWriter
+}
+
+// Flush sends the header to the client if the wrapped http.ResponseWriter supports it
+func (h *head) Flush() {
+	if f, ok := h.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection if the wrapped http.ResponseWriter supports it
+func (h *head) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := h.ResponseWriter.(http.Hijacker); ok {
+		return hj.Hijack()
+	}
+	return nil, nil, fmt.Errorf("barf: %T does not support hijacking", h.ResponseWriter)
+}
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opensaucerer/barf/router"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// respond writes a JSON response the same way the barf server does
+func respond(w http.ResponseWriter, status bool, statusCode int, message string, data map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(typing.Response{
+		Status:  status,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// go test -v -run TestRouterUnit ./...
+func TestRouterUnit(t *testing.T) {
+
+	router.Patch("/middleware/account/deposit", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Get("/middleware/account/search", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Search", "true")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("found"))
+	})
+
+	router.Version("1").Patch("/middleware/account/withdraw", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Get("/middleware/account/statement", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body>statement</body></html>"))
+		w.(http.Flusher).Flush()
+	})
+	router.Mount("/middleware/dav", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMultiStatus)
+	}))
+	router.Get("/middleware/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := Router(respond, router.Default)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	t.Run("Should respond with 405 and an Allow header for a known path", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/middleware/account/deposit", nil))
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusMethodNotAllowed)
+		}
+		if w.Header().Get("Allow") != "PATCH, OPTIONS" {
+			t.Fatalf("unexpected Allow header: got %v want %v", w.Header().Get("Allow"), "PATCH, OPTIONS")
+		}
+
+	})
+
+	t.Run("Should respond with 406 for a version the path is not registered for", func(t *testing.T) {
+
+		r := httptest.NewRequest(http.MethodPatch, "/middleware/account/withdraw", nil)
+		r.Header.Set("API-Version", "2")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotAcceptable {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusNotAcceptable)
+		}
+
+		r.Header.Set("API-Version", "1")
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK || w.Header().Get("API-Version") != "1" {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Header().Get("API-Version"))
+		}
+
+	})
+
+	t.Run("Should serve unversioned routes whatever the requested version", func(t *testing.T) {
+
+		for _, header := range []string{"API-Version", "Accept"} {
+			r := httptest.NewRequest(http.MethodGet, "/middleware/health", nil)
+			r.Header.Set(header, "2")
+			if header == "Accept" {
+				r.Header.Set(header, "application/vnd.zeina.v2+json")
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status code with %s: got %v want %v", header, w.Code, http.StatusOK)
+			}
+			if vary := w.Header().Values("Vary"); len(vary) != 2 || vary[0] != "API-Version" || vary[1] != "Accept" {
+				t.Fatalf("unexpected Vary header with %s: got %v want %v", header, vary, []string{"API-Version", "Accept"})
+			}
+		}
+
+	})
+
+	t.Run("Should pass methods of any kind to mounted handlers", func(t *testing.T) {
+
+		for _, method := range []string{http.MethodGet, "PROPFIND", "MKCOL"} {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(method, "/middleware/dav/ledger/", nil))
+
+			if w.Code != http.StatusMultiStatus {
+				t.Fatalf("unexpected status code for %s: got %v want %v", method, w.Code, http.StatusMultiStatus)
+			}
+		}
+
+	})
+
+	t.Run("Should respond with 404 for an unknown path", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/middleware/account/unknown", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusNotFound)
+		}
+
+	})
+
+	t.Run("Should serve HEAD from the GET handler without a body", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/middleware/account/search", nil))
+
+		if w.Code != http.StatusOK || w.Header().Get("X-Search") != "true" {
+			t.Fatalf("GET handler was not called: got %v", w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Fatalf("unexpected body: got %v", w.Body.String())
+		}
+
+	})
+
+	t.Run("Should sniff the Content-Type of HEAD responses like the GET response", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/middleware/account/statement", nil))
+
+		if w.Header().Get("Content-Type") != "text/html; charset=utf-8" || w.Body.Len() != 0 {
+			t.Fatalf("unexpected response: got %v %q want %v", w.Header().Get("Content-Type"), w.Body.String(), "text/html; charset=utf-8")
+		}
+		if !w.Flushed {
+			t.Fatalf("unexpected flush: got %v want %v", w.Flushed, true)
+		}
+
+	})
+
+	t.Run("Should answer OPTIONS automatically", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/middleware/account/search", nil))
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusNoContent)
+		}
+		if w.Header().Get("Allow") != "GET, HEAD, OPTIONS" {
+			t.Fatalf("unexpected Allow header: got %v want %v", w.Header().Get("Allow"), "GET, HEAD, OPTIONS")
+		}
+
+	})
+}
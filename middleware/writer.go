@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Writer wraps the http.ResponseWriter of a request served by barf. It records the status, the number of bytes
+// and the route of the response for the logger, and lets barf.Response(w) reach the request, i.e to negotiate the
+// content type of a response. It still supports http.Flusher and http.Hijacker.
+type Writer struct {
+	http.ResponseWriter
+	request *http.Request
+	// status is the status code written, 0 until the header is written
+	status int
+	// written is the number of bytes of the body written
+	written int64
+	// start is the time the request started being served
+	start time.Time
+	// route is the pattern of the route serving the request, if any
+	route string
+}
+
+// NewWriter wraps the given http.ResponseWriter of the given request
+func NewWriter(w http.ResponseWriter, r *http.Request) *Writer {
+	return &Writer{ResponseWriter: w, request: r, start: time.Now()}
+}
+
+// Request returns the request the response is written for
+func (w *Writer) Request() *http.Request {
+	return w.request
+}
+
+// WriteHeader records the status code before writing it. Informational status codes are not recorded.
+func (w *Writer) WriteHeader(code int) {
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the number of bytes written, and the implicit 200 status code of a body written without a header
+func (w *Writer) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+// Status returns the status code of the response. Responses without a header written are sent with a 200.
+func (w *Writer) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Written returns the number of bytes of the body written
+func (w *Writer) Written() int64 {
+	return w.written
+}
+
+// Duration returns the time elapsed since the request started being served
+func (w *Writer) Duration() time.Duration {
+	return time.Since(w.start)
+}
+
+// Route returns the pattern of the route serving the request, or "" if no route matched
+func (w *Writer) Route() string {
+	return w.route
+}
+
+// Unwrap returns the wrapped http.ResponseWriter. It is used by http.ResponseController.
+func (w *Writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush sends any buffered data to the client if the wrapped http.ResponseWriter supports it
+func (w *Writer) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection if the wrapped http.ResponseWriter supports it.
+// Hijacked responses are recorded with a 101 as they are upgraded to another protocol.
+func (w *Writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("barf: %T does not support hijacking", w.ResponseWriter)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// annotate records the pattern of the route serving the request on every barf writer the given writer wraps
+func annotate(w http.ResponseWriter, pattern string) {
+	for {
+		if bw, ok := w.(*Writer); ok {
+			bw.route = pattern
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = u.Unwrap()
+	}
+}
@@ -1,6 +1,33 @@
 package barf
 
-import "github.com/opensaucerer/barf/server"
+import (
+	"github.com/opensaucerer/barf/router/body"
+	"github.com/opensaucerer/barf/router/cookie"
+	"github.com/opensaucerer/barf/router/upload"
+	"github.com/opensaucerer/barf/server"
+	"github.com/opensaucerer/barf/typing"
+)
 
 // Request prepares a barf request with the given http request
 var Request = server.Request
+
+// ErrUnsupportedMediaType is returned by barf.Request(r).Body().Format for a request body it cannot decode. It should be answered with a 415.
+var ErrUnsupportedMediaType = body.ErrUnsupportedMediaType
+
+// ErrRequestEntityTooLarge is returned by barf.Request(r).Body() when the request body exceeds barf.Augment.MaxBodySize. It should be answered with a 413.
+var ErrRequestEntityTooLarge = body.ErrRequestEntityTooLarge
+
+// FieldError describes why the value of a single field of a request was rejected
+type FieldError = typing.FieldError
+
+// FieldErrors lists the fields rejected while binding or validating a request, such as by barf.Request(r).Query().Format
+type FieldErrors = typing.FieldErrors
+
+// ErrInvalidSignature is returned by barf.Request(r).Cookies().Signed for a cookie that was tampered with or signed with another secret
+var ErrInvalidSignature = cookie.ErrInvalidSignature
+
+// Form holds the values and files received with barf.Request(r).Files
+type Form = upload.Form
+
+// File is a file received with barf.Request(r).Files
+type File = upload.File
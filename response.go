@@ -1,7 +1,10 @@
 package barf
 
 import (
+	"github.com/opensaucerer/barf/encode"
+	"github.com/opensaucerer/barf/etag"
 	"github.com/opensaucerer/barf/server"
+	"github.com/opensaucerer/barf/sse"
 	"github.com/opensaucerer/barf/typing"
 )
 
@@ -10,3 +13,34 @@ var Response = server.Response
 
 // Res is a simple struct for a status based response
 type Res = typing.Response
+
+/*
+Encoder registers an encoder for the given media type such that barf.Response(w).Send can answer requests accepting it.
+It replaces the encoder already registered for the media type, if any.
+
+	barf.Encoder("application/yaml", func(w io.Writer, v interface{}) error {
+		return yaml.NewEncoder(w).Encode(v)
+	})
+*/
+var Encoder = encode.Register
+
+// EncoderFunc writes a value in the format of the media type it is registered for with barf.Encoder
+type EncoderFunc = encode.Encoder
+
+// Event is a server-sent event written with barf.Response(w).Stream()
+type Event = sse.Event
+
+// SSE holds configuration for server-sent event streams
+type SSE = typing.SSE
+
+// ErrStreamClosed is returned when sending on a stream whose client went away or that was closed
+var ErrStreamClosed = sse.ErrClosed
+
+// StrongETag returns a strong entity tag for the given representation, for barf.Response(w).ETag
+var StrongETag = etag.Strong
+
+// WeakETag returns a weak entity tag for the given representation, for barf.Response(w).ETag
+var WeakETag = etag.Weak
+
+// Error is an error carrying the HTTP status, code and fields it is answered with by barf.Response(w).Error
+type Error = typing.Error
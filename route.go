@@ -1,6 +1,11 @@
 package barf
 
-import "github.com/opensaucerer/barf/router"
+import (
+	"github.com/opensaucerer/barf/router"
+	"github.com/opensaucerer/barf/server"
+	"github.com/opensaucerer/barf/typing"
+	"github.com/opensaucerer/barf/ws"
+)
 
 // Get registers a route with the GET HTTP method
 var Get = router.Get
@@ -17,11 +22,105 @@ var Patch = router.Patch
 // Delete registers a route with the DELETE HTTP method
 var Delete = router.Delete
 
-// // Head registers a route with the HEAD HTTP method
-// var Head = router.Head
+// Head registers a route with the HEAD HTTP method.
+// HEAD requests are served from the GET handler of a path, without a body, unless a HEAD route is registered.
+var Head = router.Head
 
-// // Options registers a route with the OPTIONS HTTP method
-// var Options = router.Options
+// Options registers a route with the OPTIONS HTTP method.
+// OPTIONS requests are answered with an Allow header listing the registered methods unless an OPTIONS route is registered.
+var Options = router.Options
+
+/*
+RetroFrame creates a router whose routes are registered under the given entry path. Routers can be nested with router.RetroFrame(entry).
+
+Middleware injected with barf.Hippocampus(router).Hijack only runs for the routes of that router and the routers nested under it.
+*/
+var RetroFrame = router.RetroFrame
 
 // Any registers a route with all HTTP methods
 var Any = router.Any
+
+// RouteInfo describes a registered route
+type RouteInfo = router.Info
+
+// Routes returns every route registered with barf.Get, barf.Post... with its method, pattern, name and middleware
+var Routes = router.List
+
+/*
+URL builds the path of the route with the given name by filling its params with the given values.
+Routes are named when registered.
+
+	barf.Get("/v1/account/:number/transactions", handler).Named("account.transactions")
+	path, err := barf.URL("account.transactions", map[string]string{"number": "0123456789"})
+*/
+var URL = router.URL
+
+/*
+Host creates a router whose routes only match requests for the given host pattern.
+A * label matches a single label, or every remaining label when it comes last, and a {name} label is captured as a param.
+
+	admin := barf.Host("admin.*")
+	tenant := barf.Host("{tenant}.zeina.example")
+
+Captured labels are available with the path params through barf.Request(r).Params().
+*/
+var Host = router.Host
+
+/*
+Version creates a router whose routes are only served for the given API version, such that a path can be registered under several versions.
+
+	barf.Version("1").Patch("/v1/account/deposit", deposit)
+	barf.Version("2").Patch("/v1/account/deposit", depositWithNarration)
+
+The version is requested with the API-Version header or a vendor media type such as Accept: application/vnd.zeina.v2+json.
+Requests without a version are served by barf.Versioning.Default, or by the unversioned route of the path.
+Requests for a version the path is not registered for are served by its unversioned route too, and answered with a 406 when it has none.
+*/
+var Version = router.Version
+
+/*
+Mount delegates every method and sub-path under the given prefix to the given http.Handler, with the prefix stripped from the request path.
+
+	barf.Mount("/files", http.FileServer(http.Dir("public")))
+
+The mounted handler still runs behind the global barf.Hippocampus middleware.
+*/
+var Mount = router.Mount
+
+/*
+Static serves the files of the given file system under the given prefix, with an optional barf.Files config.
+Any fs.FS works, such as os.DirFS("public") or an embed.FS. Use fs.Sub to serve a directory of an embed.FS from its root.
+
+	barf.Static("/admin", dashboard, barf.Files{SPA: true})
+
+Content types are detected from file extensions, and range, If-Modified-Since and HEAD requests are handled.
+Paths are cleaned before they reach the file system so they cannot escape its root.
+*/
+var Static = server.Static
+
+/*
+WS registers a route upgrading GET requests on the given path to WebSocket connections. The handshake runs behind the
+global barf.Hippocampus middleware like any other request, and the connection is closed once the handler returns.
+
+	barf.WS("/v1/stream", func(conn *barf.Conn) {
+		for {
+			kind, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(kind, message)
+		}
+	})
+
+Connections are configured with barf.Augment.WebSocket.
+*/
+var WS = router.WS
+
+// Conn is a WebSocket connection served by a route registered with barf.WS
+type Conn = ws.Conn
+
+// WebSocket holds configuration for WebSocket connections
+type WebSocket = typing.WebSocket
+
+// WSCloseError is returned when reading from a WebSocket connection once it is closed, with the code and reason it was closed with
+type WSCloseError = ws.CloseError
@@ -0,0 +1,29 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/opensaucerer/barf/helper"
+)
+
+// Allowed returns the HTTP methods, in upper case, that can be used with the route path.
+// HEAD is allowed whenever GET is and OPTIONS whenever any method is.
+func (r *Route) Allowed() []string {
+	path := trim(r.Path)
+	allowed := helper.StringArray{}
+	for _, method := range methods {
+		n, _ := r.root().lookup(r.Host, path, func(n *node) bool {
+			return len(n.routes[method]) > 0 || len(n.routes[wildcard]) > 0
+		})
+		if n != nil {
+			allowed = allowed.Add(strings.ToUpper(method))
+		}
+	}
+	if len(allowed) == 0 {
+		return allowed
+	}
+	if allowed.Contains("GET") {
+		allowed = allowed.AddUnique("HEAD")
+	}
+	return allowed.AddUnique("OPTIONS")
+}
@@ -1,15 +1,20 @@
 package router
 
-import "net/http"
+import (
+	"net/http"
 
-// Any registers a route with the all HTTP method
-func Any(path string, handler func(http.ResponseWriter, *http.Request)) {
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Any registers a route with the all HTTP method.
+// The optional middleware only runs for this route, after the global and router stacks.
+func Any(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) {
+	Default.Any(path, handler, m...)
+}
+
+// Any registers a route on the router with all HTTP methods
+func (r *Router) Any(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) {
 	for _, method := range methods {
-		route := &Route{
-			Path:    path,
-			Method:  method,
-			Handler: handler,
-		}
-		route.Register()
+		r.handle(method, path, handler, m...)
 	}
 }
@@ -1,28 +1,232 @@
 package body
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/opensaucerer/barf/helper"
+	"github.com/opensaucerer/barf/typing"
+	"github.com/opensaucerer/barf/validate"
 )
 
-type B []byte
+// ErrUnsupportedMediaType is returned by Format when the request body has a content type barf cannot decode
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// ErrRequestEntityTooLarge is returned when the request body exceeds the max body size of the server
+var ErrRequestEntityTooLarge = errors.New("request body too large")
+
+// maxMemory is the number of bytes of a multipart body kept in memory, the rest is stored in temporary files
+const maxMemory = 32 << 20 // 32 MB
+
+// B holds the request body along with its content type
+type B struct {
+	raw         []byte
+	contentType string
+	// err is the error that occurred while reading the body, if any
+	err error
+}
+
+// cache holds a request body that is read at most once
+type cache struct {
+	once sync.Once
+	raw  []byte
+	err  error
+}
+
+/*
+Body reads the whole request body, including chunked bodies, and returns it along with its content type.
+
+Requests served by barf cache their body the first time it is read such that middleware and handlers can all read the same body.
+Once read, the body of the request is also replaced with a copy that can be read again.
+*/
+func Body(r *http.Request) B {
+	c, ok := r.Context().Value(typing.BodyCtxKey{}).(*cache)
+	if !ok {
+		c = &cache{}
+	}
+	c.once.Do(func() {
+		if r.Body == nil {
+			return
+		}
+		c.raw, c.err = io.ReadAll(r.Body)
+		r.Body.Close()
+	})
+	r.Body = io.NopCloser(bytes.NewReader(c.raw))
+	return B{raw: c.raw, contentType: r.Header.Get("Content-Type"), err: c.err}
+}
+
+// Cache returns a copy of r whose body is read at most once and shared by every call to Body.
+// Unless limit is negative, reading more than limit bytes of the body fails with ErrRequestEntityTooLarge.
+func Cache(r *http.Request, limit int64) *http.Request {
+	if limit >= 0 && r.Body != nil {
+		r.Body = &limited{ReadCloser: r.Body, remaining: limit}
+	}
+	return r.WithContext(context.WithValue(r.Context(), typing.BodyCtxKey{}, &cache{}))
+}
+
+// limited is a request body that fails once more than its remaining bytes are read
+type limited struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read reads from the underlying body and returns ErrRequestEntityTooLarge once the limit is exceeded
+func (l *limited) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrRequestEntityTooLarge
+	}
+	// read one byte past the limit to find out whether it is exceeded
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	if int64(n) <= l.remaining {
+		l.remaining -= int64(n)
+		return n, err
+	}
+	n = int(l.remaining)
+	l.remaining = -1
+	return n, ErrRequestEntityTooLarge
+}
 
-func Body(r *http.Request) []byte {
-	body := make([]byte, r.ContentLength)
-	r.Body.Read(body)
-	return body
+// Bytes returns the raw request body along with the error that occurred while reading it, if any
+func (b B) Bytes() ([]byte, error) {
+	return b.raw, b.err
 }
 
 // JSON formats the request body as map[string]interface{}.
-// It returns an error if the body is not a valid JSON.
+// It returns an error if the body could not be read or is not a valid JSON.
 func (b B) JSON() (map[string]interface{}, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
 	var data map[string]interface{}
-	err := json.Unmarshal(b, &data)
+	err := json.Unmarshal(b.raw, &data)
 	return data, err
 }
 
-// Format formats the request params into the given interface v which must be a pointer.
-// It returns an error if the body is not a valid JSON.
+/*
+Format formats the request body into the given interface v which must be a pointer. The decoder is chosen from the Content-Type of the request.
+
+	application/json, +json suffixes and no Content-Type    decoded with encoding/json
+	application/xml, text/xml and +xml suffixes             decoded with encoding/xml
+	application/x-www-form-urlencoded                       bound field by field
+	multipart/form-data                                     bound field by field
+
+Form and XML bodies bound into a struct share the field tags of JSON bodies. Fields are named by their `form` or `xml` tag,
+falling back to their `json` tag and then the field name, and values are converted into the type of their field.
+Only the direct child elements of the root element of an XML body are bound into a struct.
+
+Once decoded, a struct is validated against the rules of its `validate` tags and the rejected fields are returned as typing.FieldErrors, which should be answered with a 422.
+
+It returns an error wrapping ErrUnsupportedMediaType for any other Content-Type, which should be answered with a 415,
+and ErrRequestEntityTooLarge for a body exceeding the max body size of the server, which should be answered with a 413.
+*/
 func (b B) Format(v interface{}) error {
-	return json.Unmarshal(b, v)
+	if b.err != nil {
+		return b.err
+	}
+	if err := b.decode(v); err != nil {
+		return err
+	}
+	if structure(v) {
+		return validate.Struct(v)
+	}
+	return nil
+}
+
+// decode decodes the request body into v with the decoder of its content type
+func (b B) decode(v interface{}) error {
+	media := "application/json"
+	params := map[string]string{}
+	if b.contentType != "" {
+		var err error
+		if media, params, err = mime.ParseMediaType(b.contentType); err != nil {
+			return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, b.contentType)
+		}
+	}
+	switch {
+	case media == "application/json" || strings.HasSuffix(media, "+json"):
+		return json.Unmarshal(b.raw, v)
+	case media == "application/xml" || media == "text/xml" || strings.HasSuffix(media, "+xml"):
+		if !structure(v) {
+			return xml.Unmarshal(b.raw, v)
+		}
+		values, err := elements(b.raw)
+		if err != nil {
+			return err
+		}
+		return helper.Bind(v, values, "xml")
+	case media == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(b.raw))
+		if err != nil {
+			return err
+		}
+		return helper.Bind(v, values, "form")
+	case media == "multipart/form-data":
+		form, err := multipart.NewReader(bytes.NewReader(b.raw), params["boundary"]).ReadForm(maxMemory)
+		if err != nil {
+			return err
+		}
+		defer form.RemoveAll()
+		return helper.Bind(v, form.Value, "form")
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, media)
+}
+
+// structure returns true if v is a pointer to a struct
+func structure(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct
+}
+
+// elements returns the text of the direct child elements of the root element of the given XML document keyed by element name
+func elements(raw []byte) (map[string][]string, error) {
+	values := map[string][]string{}
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	depth := 0
+	var name string
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 {
+				name = t.Name.Local
+				text.Reset()
+			}
+		case xml.CharData:
+			if depth == 2 {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if depth == 2 {
+				values[name] = append(values[name], strings.TrimSpace(text.String()))
+			}
+			depth--
+		}
+	}
+	if depth != 0 || len(raw) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return values, nil
 }
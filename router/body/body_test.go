@@ -0,0 +1,140 @@
+package body
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// deposit mirrors the shape of the deposit request of the zeina app
+type deposit struct {
+	Number string   `json:"account_number"`
+	Amount float64  `json:"amount"`
+	Notify bool     `json:"notify"`
+	Tags   []string `json:"tags" form:"tag"`
+}
+
+// request creates a request with the given body and content type
+func request(body, contentType string) *http.Request {
+	r := httptest.NewRequest(http.MethodPatch, "/v1/account/deposit", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+// go test -v -run TestBodyUnit ./...
+func TestBodyUnit(t *testing.T) {
+
+	var multi bytes.Buffer
+	mw := multipart.NewWriter(&multi)
+	mw.WriteField("account_number", "0123456789")
+	mw.WriteField("amount", "2500.5")
+	mw.WriteField("notify", "true")
+	mw.WriteField("tag", "teller")
+	mw.WriteField("tag", "cash")
+	mw.Close()
+
+	cases := []struct {
+		name        string
+		body        string
+		contentType string
+	}{
+		{"Should decode JSON bodies", `{"account_number":"0123456789","amount":2500.5,"notify":true,"tags":["teller","cash"]}`, "application/json; charset=utf-8"},
+		{"Should decode JSON bodies without a content type", `{"account_number":"0123456789","amount":2500.5,"notify":true,"tags":["teller","cash"]}`, ""},
+		{"Should bind url encoded forms", "account_number=0123456789&amount=2500.5&notify=true&tag=teller&tag=cash", "application/x-www-form-urlencoded"},
+		{"Should bind multipart forms", multi.String(), mw.FormDataContentType()},
+		{"Should bind XML bodies with the shared tags", "<deposit><account_number>0123456789</account_number><amount>2500.5</amount><notify>true</notify><tags>teller</tags><tags>cash</tags></deposit>", "application/xml"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			var data deposit
+			if err := Body(request(c.body, c.contentType)).Format(&data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if data.Number != "0123456789" || data.Amount != 2500.5 || !data.Notify {
+				t.Fatalf("unexpected data: got %+v", data)
+			}
+			if len(data.Tags) != 2 || data.Tags[0] != "teller" || data.Tags[1] != "cash" {
+				t.Fatalf("unexpected tags: got %v want %v", data.Tags, []string{"teller", "cash"})
+			}
+
+		})
+	}
+
+	t.Run("Should report values that cannot be converted", func(t *testing.T) {
+
+		var data deposit
+		err := Body(request("amount=lots", "application/x-www-form-urlencoded")).Format(&data)
+		if err == nil || !strings.Contains(err.Error(), "amount") {
+			t.Fatalf("unexpected error: got %v", err)
+		}
+
+	})
+
+	t.Run("Should reject unsupported media types", func(t *testing.T) {
+
+		var data deposit
+		err := Body(request("account_number: 0123456789", "application/yaml")).Format(&data)
+		if !errors.Is(err, ErrUnsupportedMediaType) {
+			t.Fatalf("unexpected error: got %v want %v", err, ErrUnsupportedMediaType)
+		}
+
+	})
+}
+
+// go test -v -run TestBodyReadUnit ./...
+func TestBodyReadUnit(t *testing.T) {
+
+	t.Run("Should read chunked bodies in full", func(t *testing.T) {
+
+		payload := strings.Repeat("0123456789", 1000)
+		r := request(payload, "text/plain")
+		r.ContentLength = -1
+
+		raw, err := Body(r).Bytes()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(raw) != payload {
+			t.Fatalf("unexpected body length: got %v want %v", len(raw), len(payload))
+		}
+
+	})
+
+	t.Run("Should share the cached body between reads", func(t *testing.T) {
+
+		r := Cache(request(`{"account_number":"0123456789"}`, "application/json"), -1)
+		first, _ := Body(r).JSON()
+		var data deposit
+		if err := Body(r).Format(&data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if first["account_number"] != data.Number || data.Number != "0123456789" {
+			t.Fatalf("unexpected body: got %v and %v", first, data.Number)
+		}
+
+	})
+
+	t.Run("Should fail bodies exceeding the limit", func(t *testing.T) {
+
+		r := request(strings.Repeat("a", 17), "text/plain")
+		r.ContentLength = -1
+		if _, err := Body(Cache(r, 16)).Bytes(); !errors.Is(err, ErrRequestEntityTooLarge) {
+			t.Fatalf("unexpected error: got %v want %v", err, ErrRequestEntityTooLarge)
+		}
+
+		r = request(strings.Repeat("a", 16), "text/plain")
+		r.ContentLength = -1
+		if raw, err := Body(Cache(r, 16)).Bytes(); err != nil || len(raw) != 16 {
+			t.Fatalf("unexpected body: got %v bytes and %v", len(raw), err)
+		}
+
+	})
+}
@@ -0,0 +1,165 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConflictKind is the kind of conflict detected when registering a route
+type ConflictKind string
+
+const (
+	// Duplicate is reported when a route is registered twice with the same method and pattern
+	Duplicate ConflictKind = "duplicate"
+	// Ambiguous is reported when a route matches the same paths as a route of the same method but names its params or catch-all differently
+	Ambiguous ConflictKind = "ambiguous"
+	// Shadowed is reported when a route can never match because a route of the same method registered before it, with wider constraints, matches all its paths first
+	Shadowed ConflictKind = "shadowed"
+)
+
+// Conflict describes a route that clashes with a route registered before it.
+// Duplicate and ambiguous routes make barf panic with a *Conflict at registration time.
+// Shadowed routes are logged as warnings unless the router is strict.
+type Conflict struct {
+	Kind ConflictKind
+	// Method is the HTTP method of the route being registered, in upper case
+	Method string
+	// Pattern is the pattern of the route being registered
+	Pattern string
+	// Existing is the pattern of the route it conflicts with
+	Existing string
+	// Version is the API version of the route being registered, if any
+	Version string
+}
+
+// Error describes the conflict
+func (c *Conflict) Error() string {
+	switch c.Kind {
+	case Duplicate:
+		if c.Version != "" {
+			return fmt.Sprintf("barf: route %s %s is already registered for version %s", c.Method, c.Pattern, c.Version)
+		}
+		return fmt.Sprintf("barf: route %s %s is already registered", c.Method, c.Pattern)
+	case Ambiguous:
+		return fmt.Sprintf("barf: route %s %s is ambiguous with %s. Params at the same position must share the same name and constraint", c.Method, c.Pattern, c.Existing)
+	default:
+		return fmt.Sprintf("barf: route %s %s is shadowed by %s whose constraint matches every value it accepts", c.Method, c.Pattern, c.Existing)
+	}
+}
+
+// clash compares the given route with the routes registered for the same method, which are the only ones it competes with.
+// It returns an ambiguous conflict for a route matching the same paths as another one under different param names,
+// and a shadowed conflict for a route whose every path is matched first by another one. Other conflicts are nil.
+func (t *tree) clash(r *Route) *Conflict {
+	segments := strings.Split(key(r.Path), "/")
+	var shadowed *Conflict
+	var walk func(n *node) *Conflict
+	walk = func(n *node) *Conflict {
+		// routes of the wildcard method compete with every route
+		if len(n.routes[r.Method]) > 0 || (r.Method == wildcard && len(n.routes) > 0) || len(n.routes[wildcard]) > 0 {
+			switch overlap(strings.Split(n.pattern, "/"), segments) {
+			case Ambiguous:
+				return conflict(Ambiguous, r, n.pattern)
+			case Shadowed:
+				if shadowed == nil {
+					shadowed = conflict(Shadowed, r, n.pattern)
+				}
+			}
+		}
+		children := append(append(append([]*node{}, n.statics...), n.params...), n.catchAlls...)
+		for _, child := range children {
+			if c := walk(child); c != nil {
+				return c
+			}
+		}
+		return nil
+	}
+	if c := walk(t.root); c != nil {
+		return c
+	}
+	return shadowed
+}
+
+// overlap compares the segments of an existing pattern with the segments of a new one. Routes only compete when their
+// patterns line up segment for segment, as the tree backtracks into other branches whenever the rest of a path does not match.
+func overlap(existing, segments []string) ConflictKind {
+	if len(existing) != len(segments) {
+		return ""
+	}
+	// same is true while both patterns have the same shape, named is true once a param is named differently
+	same, named, wider := true, false, true
+	for i := range segments {
+		e, s := existing[i], segments[i]
+		switch {
+		case e == s:
+		case strings.HasPrefix(e, ":") && strings.HasPrefix(s, ":"):
+			en, ec := parameter(e[1:])
+			sn, sc := parameter(s[1:])
+			if ec.String() == sc.String() {
+				named = named || en != sn
+				continue
+			}
+			same = false
+			// only an earlier constrained param is tried before the new one
+			wider = wider && covers(ec, sc)
+		case strings.HasPrefix(e, "*") && strings.HasPrefix(s, "*"):
+			named = true
+		case strings.HasPrefix(e, ":") || strings.HasPrefix(e, "*") || strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*"):
+			// static segments are tried before params and catch-all segments
+			same, wider = false, false
+		default:
+			// different static segments never match the same path
+			return ""
+		}
+	}
+	switch {
+	case same && named:
+		return Ambiguous
+	case !same && wider:
+		return Shadowed
+	}
+	return ""
+}
+
+// covers returns true if every value accepted by b is also accepted by a
+func covers(a, b *constraint) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a.raw == b.raw {
+		return true
+	}
+	// the number of digits of the values accepted by b, if b only accepts digits
+	min, max, numeric := span(b.raw)
+	if b.raw == "uint" {
+		// 18446744073709551615
+		min, max, numeric = 1, 20, true
+	}
+	switch a.raw {
+	case "int":
+		// 9223372036854775807 has 19 digits, so any 18 digits fit
+		return numeric && max > 0 && max <= 18
+	case "uint":
+		return numeric && max > 0 && max <= 19
+	case "float":
+		// floats only overflow past 308 digits
+		return b.raw == "int" || (numeric && max > 0 && max <= 308)
+	case "alnum":
+		return b.raw == "alpha" || numeric
+	}
+	if amin, amax, ok := span(a.raw); ok {
+		return numeric && min >= amin && (amax < 0 || (max > 0 && max <= amax))
+	}
+	return false
+}
+
+// conflict creates a conflict for the given route
+func conflict(kind ConflictKind, r *Route, existing string) *Conflict {
+	return &Conflict{
+		Kind:     kind,
+		Method:   strings.ToUpper(r.Method),
+		Pattern:  key(r.Path),
+		Existing: existing,
+		Version:  r.Version,
+	}
+}
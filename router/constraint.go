@@ -0,0 +1,131 @@
+package router
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// constraint restricts the values a path parameter can match
+type constraint struct {
+	// raw is the constraint as written in the route pattern i.e digits{10}
+	raw   string
+	match func(string) bool
+}
+
+// uuid matches a canonical textual representation of a UUID
+var uuid = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// digits matches the digits{n} and digits{min,max} constraints
+var digits = regexp.MustCompile(`^digits(?:\{(\d+)(?:,(\d*))?\})?$`)
+
+// parameter splits a param segment such as :number<digits{10}> into its name and constraint
+func parameter(s string) (string, *constraint) {
+	start := strings.IndexByte(s, '<')
+	if start < 0 {
+		return s, nil
+	}
+	if !strings.HasSuffix(s, ">") {
+		panic(fmt.Sprintf("barf: missing closing > in path param constraint :%s", s))
+	}
+	return s[:start], compile(s[start+1 : len(s)-1])
+}
+
+/*
+compile creates a constraint from its raw form. The following constraints are supported
+
+	int, uint, float, bool, alpha, alnum, uuid
+	digits, digits{n}, digits{min,} and digits{min,max}
+
+Anything else is compiled as a regular expression which must match the whole segment.
+*/
+func compile(raw string) *constraint {
+	c := &constraint{raw: raw}
+	switch raw {
+	case "int":
+		c.match = func(s string) bool {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		}
+	case "uint":
+		c.match = func(s string) bool {
+			_, err := strconv.ParseUint(s, 10, 64)
+			return err == nil
+		}
+	case "float":
+		c.match = func(s string) bool {
+			_, err := strconv.ParseFloat(s, 64)
+			return err == nil
+		}
+	case "bool":
+		c.match = func(s string) bool {
+			_, err := strconv.ParseBool(s)
+			return err == nil
+		}
+	case "alpha":
+		c.match = func(s string) bool {
+			return every(s, func(b byte) bool { return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') })
+		}
+	case "alnum":
+		c.match = func(s string) bool {
+			return every(s, func(b byte) bool {
+				return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+			})
+		}
+	case "uuid":
+		c.match = uuid.MatchString
+	default:
+		if min, max, ok := span(raw); ok {
+			c.match = func(s string) bool {
+				return len(s) >= min && (max < 0 || len(s) <= max) && every(s, func(b byte) bool { return b >= '0' && b <= '9' })
+			}
+			break
+		}
+		re, err := regexp.Compile("^(?:" + raw + ")$")
+		if err != nil {
+			panic(fmt.Sprintf("barf: invalid path param constraint <%s>: %s", raw, err))
+		}
+		c.match = re.MatchString
+	}
+	return c
+}
+
+// span returns the minimum and maximum number of digits accepted by a digits constraint, -1 for no maximum.
+// It returns false for any other constraint.
+func span(raw string) (int, int, bool) {
+	m := digits.FindStringSubmatch(raw)
+	if m == nil {
+		return 0, 0, false
+	}
+	min, max := 1, -1
+	if m[1] != "" {
+		min, _ = strconv.Atoi(m[1])
+		max = min
+		if strings.Contains(raw, ",") {
+			max = -1
+			if m[2] != "" {
+				max, _ = strconv.Atoi(m[2])
+			}
+		}
+	}
+	return min, max, true
+}
+
+// String returns the constraint as written in the route pattern or an empty string for a nil constraint
+func (c *constraint) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.raw
+}
+
+// every returns true if f returns true for every byte of s
+func every(s string, f func(byte) bool) bool {
+	for i := 0; i < len(s); i++ {
+		if !f(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package cookie
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidSignature is returned when a signed cookie was tampered with or signed with another secret
+var ErrInvalidSignature = errors.New("invalid cookie signature")
+
+// C holds the cookies of a request along with the secret signed cookies are verified with
+type C struct {
+	request *http.Request
+	secret  []byte
+}
+
+func Cookies(r *http.Request, secret string) C {
+	return C{request: r, secret: []byte(secret)}
+}
+
+// Get returns the value of the cookie with the given name. It returns http.ErrNoCookie if the cookie is not set.
+func (c C) Get(name string) (string, error) {
+	cookie, err := c.request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return cookie.Value, nil
+}
+
+// Signed returns the value of the cookie with the given name after verifying its signature.
+// It returns http.ErrNoCookie if the cookie is not set and ErrInvalidSignature if its signature does not match.
+func (c C) Signed(name string) (string, error) {
+	raw, err := c.Get(name)
+	if err != nil {
+		return "", err
+	}
+	i := strings.LastIndexByte(raw, '.')
+	if i < 0 || len(c.secret) == 0 {
+		return "", ErrInvalidSignature
+	}
+	value, signature := raw[:i], raw[i+1:]
+	expected := sign(c.secret, name, value)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return "", ErrInvalidSignature
+	}
+	return value, nil
+}
+
+// Sign returns a copy of the given cookie whose value is signed with the secret, ready to be set with http.SetCookie.
+// It panics if no secret is configured.
+func (c C) Sign(cookie *http.Cookie) *http.Cookie {
+	if len(c.secret) == 0 {
+		panic("barf: signing cookies requires a cookie secret, set barf.Augment.CookieSecret")
+	}
+	signed := *cookie
+	signed.Value = cookie.Value + "." + sign(c.secret, cookie.Name, cookie.Value)
+	return &signed
+}
+
+// sign returns the HMAC-SHA256 signature of the cookie with the given name and value
+func sign(secret []byte, name, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(name + "=" + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
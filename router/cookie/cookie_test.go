@@ -0,0 +1,50 @@
+package cookie
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -run TestCookieUnit ./...
+func TestCookieUnit(t *testing.T) {
+
+	signed := Cookies(httptest.NewRequest(http.MethodGet, "/", nil), "secret").Sign(&http.Cookie{Name: "session", Value: "0123456789"})
+
+	cases := []struct {
+		name   string
+		secret string
+		value  string
+		err    error
+	}{
+		{"Should verify cookies signed with the secret", "secret", signed.Value, nil},
+		{"Should reject tampered cookies", "secret", "9876543210" + signed.Value[10:], ErrInvalidSignature},
+		{"Should reject cookies signed with another secret", "other", signed.Value, ErrInvalidSignature},
+		{"Should reject unsigned cookies", "secret", "0123456789", ErrInvalidSignature},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(&http.Cookie{Name: "session", Value: c.value})
+			value, err := Cookies(r, c.secret).Signed("session")
+			if !errors.Is(err, c.err) {
+				t.Fatalf("unexpected error: got %v want %v", err, c.err)
+			}
+			if c.err == nil && value != "0123456789" {
+				t.Fatalf("unexpected value: got %v want %v", value, "0123456789")
+			}
+
+		})
+	}
+
+	t.Run("Should report missing cookies", func(t *testing.T) {
+
+		if _, err := Cookies(httptest.NewRequest(http.MethodGet, "/", nil), "secret").Get("session"); !errors.Is(err, http.ErrNoCookie) {
+			t.Fatalf("unexpected error: got %v want %v", err, http.ErrNoCookie)
+		}
+
+	})
+}
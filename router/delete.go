@@ -1,13 +1,19 @@
 package router
 
-import "net/http"
-
-// Delete registers a route with the DELETE HTTP method
-func Delete(path string, handler func(http.ResponseWriter, *http.Request)) {
-	route := &Route{
-		Path:    path,
-		Method:  delete,
-		Handler: handler,
-	}
-	route.Register()
+import (
+	"net/http"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Delete registers a route with the DELETE HTTP method.
+// The optional middleware only runs for this route, after the global and router stacks.
+// The returned route can be named with route.Named for reverse URL building.
+func Delete(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return Default.Delete(path, handler, m...)
+}
+
+// Delete registers a route on the router with the DELETE HTTP method
+func (r *Router) Delete(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return r.handle(delete, path, handler, m...)
 }
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// RetroFrame creates a router whose routes are registered on the default router under the given entry path.
+// Middleware injected into the router through barf.Hippocampus(router).Hijack only runs for its routes.
+func RetroFrame(entry string) *Router {
+	return Default.RetroFrame(entry)
+}
+
+// RetroFrame creates a router nested under r. Routes registered on the nested router run the middleware stack of r before its own.
+func (r *Router) RetroFrame(entry string) *Router {
+	return &Router{
+		Entry:  trim(entry),
+		Stack:  []typing.Middleware{},
+		parent: r,
+	}
+}
+
+// prefix returns the full entry path of the router including that of its parents
+func (r *Router) prefix() string {
+	if r == nil {
+		return ""
+	}
+	return strings.TrimSuffix(r.parent.prefix()+"/"+strings.Trim(r.Entry, "/"), "/")
+}
+
+// handle registers a route on the router with the given method and returns it
+func (r *Router) handle(method, path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	route := &Route{
+		Path:    r.prefix() + "/" + strings.Trim(path, "/"),
+		Method:  method,
+		Handler: handler,
+		Stack:   m,
+		Router:  r,
+	}
+	if h := r.hostname(); h != nil {
+		route.Host = h.pattern
+	}
+	route.Version = r.version()
+	route.Register()
+	r.Routes = append(r.Routes, route)
+	return route
+}
+
+// owns returns true if the given route was registered on r or any router nested under it
+func (r *Router) owns(route *Route) bool {
+	for rt := route.Router; rt != nil; rt = rt.parent {
+		if rt == r {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeHTTP dispatches the request to the matching route registered on the router or any router nested under it.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	lookup := Route{
+		Path:    trim(Path(req.URL)),
+		Method:  strings.ToLower(req.Method),
+		Host:    req.Host,
+		Router:  r,
+		Version: r.root().Negotiate(req),
+	}
+	route, params := lookup.find(r)
+	if route == nil {
+		// the route may only be missing for the requested version
+		r.root().Announce(w, lookup.Version, "")
+		http.NotFound(w, req)
+		return
+	}
+	r.root().Announce(w, lookup.Version, route.Version)
+	ctx := context.WithValue(req.Context(), typing.ParamsCtxKey{}, params)
+	route.chain()(w, req.WithContext(ctx))
+}
@@ -0,0 +1,109 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// trace returns a middleware that appends the given name to the X-Trace response header
+func trace(name string) typing.Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", name)
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// go test -v -run TestFrameUnit ./...
+func TestFrameUnit(t *testing.T) {
+
+	v1 := RetroFrame("/frame/v1/")
+	account := v1.RetroFrame("account")
+	v1.Stack = append(v1.Stack, trace("v1"))
+	account.Stack = append(account.Stack, trace("account"))
+
+	account.Get("/:number", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Trace", "handler")
+		w.WriteHeader(http.StatusOK)
+	}, trace("route"))
+	v1.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Trace", "handler")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("Should register routes under the router prefix", func(t *testing.T) {
+
+		if len(account.Routes) != 1 || account.Routes[0].Path != "frame/v1/account/:number" {
+			t.Fatalf("unexpected routes: got %v", account.Routes)
+		}
+
+	})
+
+	t.Run("Should run parent stacks before nested stacks and route middleware last", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		v1.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/frame/v1/account/0123456789", nil))
+
+		got := strings.Join(w.Header().Values("X-Trace"), ",")
+		if got != "v1,account,route,handler" {
+			t.Fatalf("unexpected middleware order: got %v want %v", got, "v1,account,route,handler")
+		}
+
+	})
+
+	t.Run("Should only run the stack of the router the route was registered on", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		v1.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/frame/v1/health", nil))
+
+		got := strings.Join(w.Header().Values("X-Trace"), ",")
+		if got != "v1,handler" {
+			t.Fatalf("unexpected middleware order: got %v want %v", got, "v1,handler")
+		}
+
+	})
+
+	t.Run("Should not serve routes registered on a sibling router", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		account.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/frame/v1/health", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusNotFound)
+		}
+
+	})
+
+	t.Run("Should compose the middleware chain once until a stack changes", func(t *testing.T) {
+
+		rt := New()
+		built := 0
+		counted := func(h http.Handler) http.Handler {
+			built++
+			return h
+		}
+		rt.Get("/account/search", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}, counted)
+
+		for i := 0; i < 3; i++ {
+			rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/account/search", nil))
+		}
+		if built != 1 {
+			t.Fatalf("unexpected compositions: got %v want %v", built, 1)
+		}
+
+		rt.Stack = append(rt.Stack, trace("root"))
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/account/search", nil))
+		if built != 2 || w.Header().Get("X-Trace") != "root" {
+			t.Fatalf("unexpected chain after the stack changed: got %v %v want %v %v", built, w.Header().Get("X-Trace"), 2, "root")
+		}
+
+	})
+}
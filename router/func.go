@@ -1,42 +1,30 @@
 package router
 
-import (
-	"regexp"
-	"strings"
-)
-
-// Func retrieves the handler function for the given path and method
+// Func retrieves the handler function for the given path, method, host and requested API version.
+// Once found, the version of the route is the version that will serve the request.
 func (r *Route) Func() {
 	// remove preceding and trailing slashes
-	r.Path = regexp.MustCompile("^/+|/+$").ReplaceAllString(r.Path, "")
-	if r.Path == "" {
-		r.Path = "/"
-	}
-	// if path found in top level of table
-	if table[r.Path] != nil && table[r.Path][r.Method] != nil {
-		r.Handler = table[r.Path][r.Method]
+	r.Path = trim(r.Path)
+	route, params := r.find(nil)
+	if route == nil {
 		return
 	}
+	r.Handler = route.chain()
+	r.Params = params
+	r.Version = route.Version
+	r.Pattern = key(route.Path)
+}
 
-	// handle path with parameters
-	paths := strings.Split(r.Path, "/")
-TLoop:
-	for path, methods := range table {
-		variables := strings.Split(path, "/")
-		if len(paths) == len(variables) && methods[r.Method] != nil {
-			match := true
-		VLoop:
-			for i, variable := range variables {
-				if variable != paths[i] && (len(variable) == 0 || variable[0] != ':') {
-					match = false
-					break VLoop
-				}
-			}
-			if match {
-				r.Handler = methods[r.Method]
-				r.Params = Params(r.Path, path)
-				break TLoop
-			}
-		}
+// find returns the registered route matching r, along with its params, optionally restricted to the routes of the given router
+func (r *Route) find(owner *Router) (*Route, map[string]string) {
+	root := r.root()
+	var route *Route
+	n, params := root.lookup(r.Host, r.Path, func(n *node) bool {
+		route = n.route(r.Method, r.Version, root.versioning().Default)
+		return route != nil && (owner == nil || owner.owns(route))
+	})
+	if n == nil {
+		return nil, nil
 	}
+	return route, params
 }
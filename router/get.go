@@ -1,13 +1,19 @@
 package router
 
-import "net/http"
-
-// Get registers a route with the GET HTTP method
-func Get(path string, handler func(http.ResponseWriter, *http.Request)) {
-	route := &Route{
-		Path:    path,
-		Method:  get,
-		Handler: handler,
-	}
-	route.Register()
+import (
+	"net/http"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Get registers a route with the GET HTTP method.
+// The optional middleware only runs for this route, after the global and router stacks.
+// The returned route can be named with route.Named for reverse URL building.
+func Get(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return Default.Get(path, handler, m...)
+}
+
+// Get registers a route on the router with the GET HTTP method
+func (r *Router) Get(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return r.handle(get, path, handler, m...)
 }
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Head registers a route with the HEAD HTTP method.
+// The optional middleware only runs for this route, after the global and router stacks.
+// The returned route can be named with route.Named for reverse URL building.
+func Head(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return Default.Head(path, handler, m...)
+}
+
+// Head registers a route on the router with the HEAD HTTP method
+func (r *Router) Head(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return r.handle(head, path, handler, m...)
+}
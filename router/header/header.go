@@ -0,0 +1,81 @@
+package header
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// H holds the headers of a request
+type H struct {
+	header http.Header
+}
+
+func Header(r *http.Request) H {
+	return H{header: r.Header}
+}
+
+// Get returns the first value of the header with the given case insensitive name, or an empty string if it is not set
+func (h H) Get(name string) string {
+	return h.header.Get(name)
+}
+
+// Values returns every value of the header with the given name, including the comma separated values of a single line
+func (h H) Values(name string) []string {
+	var values []string
+	for _, line := range h.header.Values(name) {
+		for _, v := range strings.Split(line, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// Has returns true if the header with the given name is set
+func (h H) Has(name string) bool {
+	return len(h.header.Values(name)) > 0
+}
+
+// Int returns the value of the header with the given name as an int.
+// It returns an error if the header is not set or is not a valid int.
+func (h H) Int(name string) (int, error) {
+	v, err := strconv.Atoi(strings.TrimSpace(h.Get(name)))
+	if err != nil {
+		return 0, fmt.Errorf("header %s is not a valid int", http.CanonicalHeaderKey(name))
+	}
+	return v, nil
+}
+
+// Bool returns the value of the header with the given name as a bool.
+// It returns an error if the header is not set or is not a valid bool.
+func (h H) Bool(name string) (bool, error) {
+	v, err := strconv.ParseBool(strings.TrimSpace(h.Get(name)))
+	if err != nil {
+		return false, fmt.Errorf("header %s is not a valid bool", http.CanonicalHeaderKey(name))
+	}
+	return v, nil
+}
+
+// Time returns the value of the header with the given name as a time, such as If-Modified-Since.
+// It returns an error if the header is not set or is not a valid HTTP date.
+func (h H) Time(name string) (time.Time, error) {
+	v, err := http.ParseTime(h.Get(name))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("header %s is not a valid HTTP date", http.CanonicalHeaderKey(name))
+	}
+	return v, nil
+}
+
+// Bearer returns the token of an Authorization header using the Bearer scheme and whether there was one
+func (h H) Bearer() (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(h.Get("Authorization")), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
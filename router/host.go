@@ -0,0 +1,130 @@
+package router
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// host holds the routes registered for a host pattern
+type host struct {
+	pattern string
+	re      *regexp.Regexp
+	tree    *tree
+}
+
+// label matches a {name} label of a host pattern
+var label = regexp.MustCompile(`^\{(\w+)\}$`)
+
+/*
+compileHost creates a host from the given pattern. Patterns are matched label by label, case insensitively, and ignore the port.
+
+	admin.*               * matches a single label, or every remaining label when it comes last
+	{tenant}.zeina.example   {name} captures a single label as a param
+*/
+func compileHost(pattern string) *host {
+	labels := strings.Split(strings.ToLower(strings.Trim(pattern, ".")), ".")
+	for i, l := range labels {
+		switch {
+		case l == "*" && i == len(labels)-1:
+			labels[i] = `.+`
+		case l == "*":
+			labels[i] = `[^.]+`
+		case label.MatchString(l):
+			labels[i] = `(?P<` + label.FindStringSubmatch(l)[1] + `>[^.]+)`
+		default:
+			labels[i] = regexp.QuoteMeta(l)
+		}
+	}
+	re, err := regexp.Compile(`^` + strings.Join(labels, `\.`) + `$`)
+	if err != nil {
+		panic(fmt.Sprintf("barf: invalid host pattern %s: %s", pattern, err))
+	}
+	return &host{pattern: pattern, re: re, tree: newTree()}
+}
+
+// match returns the params captured from the given request host and whether it matches the pattern
+func (h *host) match(hostname string) (map[string]string, bool) {
+	// remove the port, if any, without breaking IPv6 addresses
+	if i := strings.LastIndexByte(hostname, ':'); i >= 0 && !strings.Contains(hostname[i:], "]") {
+		hostname = hostname[:i]
+	}
+	m := h.re.FindStringSubmatch(strings.ToLower(hostname))
+	if m == nil {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, name := range h.re.SubexpNames() {
+		if name != "" {
+			params[name] = m[i]
+		}
+	}
+	return params, true
+}
+
+// Host creates a router on the default router whose routes only match requests for the given host pattern.
+func Host(pattern string) *Router {
+	return Default.Host(pattern)
+}
+
+/*
+Host creates a router nested under r whose routes only match requests for the given host pattern.
+Routes registered for a host take priority over routes registered without one.
+
+	admin := barf.Host("admin.*")
+	tenant := barf.Host("{tenant}.zeina.example")
+
+Labels captured with {name} are available with the path params through barf.Request(r).Params().
+*/
+func (r *Router) Host(pattern string) *Router {
+	root := r.root()
+	var h *host
+	for _, existing := range root.hosts {
+		if existing.pattern == pattern {
+			h = existing
+		}
+	}
+	if h == nil {
+		h = compileHost(pattern)
+		root.hosts = append(root.hosts, h)
+	}
+	return &Router{
+		Entry:  "/",
+		Stack:  []typing.Middleware{},
+		parent: r,
+		host:   h,
+	}
+}
+
+// hostname returns the host the router is bound to, if any
+func (r *Router) hostname() *host {
+	for ; r != nil; r = r.parent {
+		if r.host != nil {
+			return r.host
+		}
+	}
+	return nil
+}
+
+// lookup returns the node matching the given request host and path for which ok returns true, along with the captured host and path params.
+// Routes registered for a matching host pattern are tried before routes registered without one.
+func (r *Router) lookup(hostname, path string, ok func(*node) bool) (*node, map[string]string) {
+	root := r.root()
+	for _, h := range root.hosts {
+		captured, matched := h.match(hostname)
+		if !matched {
+			continue
+		}
+		if n, params := h.tree.lookup(path, ok); n != nil {
+			for k, v := range captured {
+				if _, exists := params[k]; !exists {
+					params[k] = v
+				}
+			}
+			return n, params
+		}
+	}
+	return root.tree.lookup(path, ok)
+}
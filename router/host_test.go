@@ -0,0 +1,72 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// go test -v -run TestHostUnit ./...
+func TestHostUnit(t *testing.T) {
+
+	echo := func(source string) func(http.ResponseWriter, *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Source", source)
+			json.NewEncoder(w).Encode(r.Context().Value(typing.ParamsCtxKey{}))
+		}
+	}
+
+	rt := New()
+	rt.Get("/v1/account/:number", echo("public"))
+	rt.Host("admin.*").Get("/v1/account/:number", echo("admin"))
+	rt.Host("{tenant}.zeina.example").RetroFrame("/v1").Get("/account/:number", echo("tenant"))
+
+	cases := []struct {
+		name   string
+		host   string
+		source string
+		params map[string]string
+	}{
+		{"Should prefer routes registered for a matching host", "admin.zeina.example", "admin", map[string]string{"number": "01"}},
+		{"Should ignore the port and case of the host", "ADMIN.internal:8080", "admin", map[string]string{"number": "01"}},
+		{"Should capture host labels as params", "lagos.zeina.example", "tenant", map[string]string{"number": "01", "tenant": "lagos"}},
+		{"Should fall back to routes registered without a host", "api.example.com", "public", map[string]string{"number": "01"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/account/01", nil)
+			r.Host = c.host
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, r)
+
+			if got := w.Header().Get("X-Source"); got != c.source {
+				t.Fatalf("unexpected route: got %v want %v", got, c.source)
+			}
+			var params map[string]string
+			json.NewDecoder(w.Body).Decode(&params)
+			if len(params) != len(c.params) {
+				t.Fatalf("unexpected params: got %v want %v", params, c.params)
+			}
+			for k, v := range c.params {
+				if params[k] != v {
+					t.Fatalf("unexpected params: got %v want %v", params, c.params)
+				}
+			}
+
+		})
+	}
+
+	t.Run("Should list host routes with their host pattern", func(t *testing.T) {
+
+		infos := rt.List()
+		if len(infos) != 3 || infos[0].Host != "" || infos[1].Host != "admin.*" || infos[2].Host != "{tenant}.zeina.example" {
+			t.Fatalf("unexpected routes: got %v", infos)
+		}
+
+	})
+}
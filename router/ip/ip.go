@@ -0,0 +1,103 @@
+package ip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Parse parses the given IP addresses and CIDR ranges of trusted proxies
+func Parse(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s", proxy)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+/*
+Client returns the IP address of the client that sent the request.
+
+Forwarding headers can be spoofed by clients, so they are only read when the request comes from one of the given trusted proxies.
+The Forwarded header is preferred over X-Forwarded-For, and its addresses are walked from the closest hop to the farthest,
+skipping trusted proxies, such that the first address that is not a trusted proxy is the client.
+*/
+func Client(r *http.Request, trusted []*net.IPNet) string {
+	remote := host(r.RemoteAddr)
+	if !contains(trusted, remote) {
+		return remote
+	}
+	hops := forwarded(r.Header.Values("Forwarded"))
+	if len(hops) == 0 {
+		for _, line := range r.Header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(line, ",") {
+				hops = append(hops, host(strings.TrimSpace(hop)))
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if net.ParseIP(hops[i]) == nil {
+			// an obfuscated or unknown hop can not be walked past
+			break
+		}
+		if !contains(trusted, hops[i]) || i == 0 {
+			return hops[i]
+		}
+	}
+	return remote
+}
+
+// forwarded returns the addresses of the for parameters of the given Forwarded headers
+func forwarded(lines []string) []string {
+	var hops []string
+	for _, line := range lines {
+		for _, element := range strings.Split(line, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(key, "for") {
+					hops = append(hops, host(strings.Trim(value, `"`)))
+				}
+			}
+		}
+	}
+	return hops
+}
+
+// host removes the port and IPv6 brackets from the given address
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+	return strings.Trim(addr, "[]")
+}
+
+// contains returns true if the given address belongs to any of the given networks
+func contains(nets []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package ip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -run TestClientUnit ./...
+func TestClientUnit(t *testing.T) {
+
+	trusted, err := Parse([]string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		remote string
+		header string
+		value  string
+		client string
+	}{
+		{"Should use the remote address without forwarding headers", "203.0.113.7:51234", "", "", "203.0.113.7"},
+		{"Should ignore forwarding headers from untrusted peers", "203.0.113.7:51234", "X-Forwarded-For", "198.51.100.1", "203.0.113.7"},
+		{"Should honour X-Forwarded-For from trusted proxies", "10.0.0.2:443", "X-Forwarded-For", "198.51.100.1, 10.0.0.3", "198.51.100.1"},
+		{"Should not trust addresses spoofed ahead of the client", "10.0.0.2:443", "X-Forwarded-For", "1.1.1.1, 198.51.100.1, 192.168.1.1", "198.51.100.1"},
+		{"Should prefer the Forwarded header", "10.0.0.2:443", "Forwarded", `for=198.51.100.9;proto=https, for="[fd00::1]:8080"`, "198.51.100.9"},
+		{"Should return the farthest hop when every hop is trusted", "[fd00::2]:443", "X-Forwarded-For", "10.0.0.9, 10.0.0.8", "10.0.0.9"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = c.remote
+			if c.header != "" {
+				r.Header.Set(c.header, c.value)
+			}
+			if got := Client(r, trusted); got != c.client {
+				t.Fatalf("unexpected client: got %v want %v", got, c.client)
+			}
+
+		})
+	}
+
+	t.Run("Should reject invalid proxies", func(t *testing.T) {
+
+		if _, err := Parse([]string{"10.0.0.0/33"}); err == nil {
+			t.Fatal("expected error but got none")
+		}
+
+	})
+}
@@ -0,0 +1,123 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Info describes a registered route
+type Info struct {
+	// Host is the host pattern of the route, if any
+	Host string `json:"host,omitempty"`
+	// Method is the HTTP method of the route in upper case
+	Method string `json:"method"`
+	// Pattern is the path pattern of the route
+	Pattern string `json:"pattern"`
+	// Version is the API version of the route, if any
+	Version string `json:"version,omitempty"`
+	// Name is the name of the route, if any
+	Name string `json:"name,omitempty"`
+	// Middleware lists the functions of the router and route stacks in the order they run
+	Middleware []string `json:"middleware"`
+}
+
+// List returns every route registered on the default router
+func List() []Info {
+	return Default.List()
+}
+
+// List returns every route registered on the router or any router nested under it, sorted by host, pattern, method and version
+func (r *Router) List() []Info {
+	infos := []Info{}
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n == nil {
+			return
+		}
+		for _, method := range append(methods, wildcard) {
+			for _, route := range n.routes[method] {
+				if r.owns(route) {
+					infos = append(infos, route.info())
+				}
+			}
+		}
+		for _, child := range n.statics {
+			walk(child)
+		}
+		for _, child := range n.params {
+			walk(child)
+		}
+		for _, child := range n.catchAlls {
+			walk(child)
+		}
+	}
+	walk(r.root().tree.root)
+	for _, h := range r.root().hosts {
+		walk(h.tree.root)
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].Host != infos[j].Host {
+			return infos[i].Host < infos[j].Host
+		}
+		if infos[i].Pattern != infos[j].Pattern {
+			return infos[i].Pattern < infos[j].Pattern
+		}
+		if infos[i].Method != infos[j].Method {
+			return order(infos[i].Method) < order(infos[j].Method)
+		}
+		return infos[i].Version < infos[j].Version
+	})
+	return infos
+}
+
+// order returns the position of the given method in the order methods are listed in
+func order(method string) int {
+	for i, m := range methods {
+		if strings.EqualFold(m, method) {
+			return i
+		}
+	}
+	return len(methods)
+}
+
+// Print writes the route table of the router to w in aligned columns
+func (r *Router) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tMETHOD\tPATTERN\tVERSION\tNAME\tMIDDLEWARE")
+	for _, info := range r.List() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", info.Host, info.Method, info.Pattern, info.Version, info.Name, strings.Join(info.Middleware, ", "))
+	}
+	tw.Flush()
+}
+
+// info describes the route
+func (r *Route) info() Info {
+	stack := []string{}
+	for rt := r.Router; rt != nil; rt = rt.parent {
+		stack = append(names(rt.Stack), stack...)
+	}
+	return Info{
+		Host:       r.Host,
+		Method:     strings.ToUpper(r.Method),
+		Pattern:    key(r.Path),
+		Version:    r.Version,
+		Name:       r.Name,
+		Middleware: append(stack, names(r.Stack)...),
+	}
+}
+
+// names returns the function names of the given middleware
+func names(stack []typing.Middleware) []string {
+	list := []string{}
+	for _, m := range stack {
+		list = append(list, runtime.FuncForPC(reflect.ValueOf(m).Pointer()).Name())
+	}
+	return list
+}
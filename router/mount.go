@@ -0,0 +1,50 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Mount delegates every method and sub-path under the given prefix on the default router to the given handler, with the prefix stripped.
+func Mount(prefix string, handler http.Handler, m ...typing.Middleware) {
+	Default.Mount(prefix, handler, m...)
+}
+
+/*
+Mount delegates every method and sub-path under the given prefix to the given handler, with the prefix stripped from the request path.
+This allows any http.Handler such as http.FileServer or a third-party router to serve a subtree of the router.
+
+	barf.Mount("/files", http.FileServer(http.Dir("public")))
+
+The mounted handler still runs behind the global, router and optional route middleware.
+Routes registered for a method under the prefix take precedence over the mounted handler.
+*/
+func (r *Router) Mount(prefix string, handler http.Handler, m ...typing.Middleware) {
+	mount := strings.Trim(r.prefix()+"/"+strings.Trim(prefix, "/"), "/")
+	// registered for the wildcard method such that methods like PROPFIND or PURGE reach the handler too
+	r.handle(wildcard, prefix+"/*path", func(w http.ResponseWriter, req *http.Request) {
+		handler.ServeHTTP(w, strip(req, mount))
+	}, m...)
+}
+
+// strip returns a shallow copy of the request whose path no longer starts with the given prefix
+func strip(req *http.Request, prefix string) *http.Request {
+	path := strings.TrimLeft(req.URL.Path, "/")
+	if !strings.HasPrefix(path, prefix) {
+		return req
+	}
+	path = path[len(prefix):]
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	r := new(http.Request)
+	*r = *req
+	r.URL = new(url.URL)
+	*r.URL = *req.URL
+	r.URL.Path = path
+	r.URL.RawPath = ""
+	return r
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -run TestMountUnit ./...
+func TestMountUnit(t *testing.T) {
+
+	rt := New()
+	rt.RetroFrame("/v1").Mount("/debug", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	cases := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/v1/debug", "/"},
+		{http.MethodGet, "/v1/debug/", "/"},
+		{http.MethodPost, "/v1/debug/pprof/heap", "/pprof/heap"},
+		{http.MethodDelete, "/v1/debug/pprof/", "/pprof/"},
+		{"PROPFIND", "/v1/debug/files/", "/files/"},
+		{"PURGE", "/v1/debug/cache", "/cache"},
+	}
+
+	for _, c := range cases {
+		t.Run("Should strip the prefix from "+c.method+" "+c.path, func(t *testing.T) {
+
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, httptest.NewRequest(c.method, c.path, nil))
+
+			if w.Code != http.StatusTeapot {
+				t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusTeapot)
+			}
+			if got := w.Header().Get("X-Path"); got != c.want {
+				t.Fatalf("unexpected path: got %v want %v", got, c.want)
+			}
+
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Named gives the route a name unique to its root router such that its URL can be built with barf.URL.
+// It panics if the name is already taken by another route.
+func (r *Route) Named(name string) *Route {
+	names := r.root().tree.names
+	if existing, ok := names[name]; ok && existing != r {
+		panic(fmt.Sprintf("barf: route name %s is already taken by %s %s", name, strings.ToUpper(existing.Method), key(existing.Path)))
+	}
+	r.Name = name
+	names[name] = r
+	return r
+}
+
+// URL builds the path of the route with the given name on the default router by filling its params with the given values.
+func URL(name string, params map[string]string) (string, error) {
+	return Default.URL(name, params)
+}
+
+// URL builds the path of the route with the given name by filling its params with the given values.
+// It returns an error if the route does not exist, a param is missing or a value does not satisfy the param constraint.
+func (r *Router) URL(name string, params map[string]string) (string, error) {
+	route, ok := r.root().tree.names[name]
+	if !ok {
+		return "", fmt.Errorf("barf: no route named %s", name)
+	}
+	segments := strings.Split(strings.Trim(route.Path, "/"), "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name, c := parameter(segment[1:])
+			value, ok := params[name]
+			if !ok || value == "" {
+				return "", fmt.Errorf("barf: missing param %s for route %s", name, route.Name)
+			}
+			if c != nil && !c.match(value) {
+				return "", fmt.Errorf("barf: param %s=%s does not satisfy constraint <%s> of route %s", name, value, c.raw, route.Name)
+			}
+			segments[i] = url.PathEscape(value)
+		case strings.HasPrefix(segment, "*"):
+			parts := strings.Split(strings.Trim(params[segment[1:]], "/"), "/")
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+			segments[i] = strings.Join(parts, "/")
+		}
+	}
+	return strings.TrimSuffix("/"+strings.Join(segments, "/"), "/"), nil
+}
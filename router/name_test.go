@@ -0,0 +1,108 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// go test -v -run TestNameUnit ./...
+func TestNameUnit(t *testing.T) {
+
+	rt := New()
+	account := rt.RetroFrame("/v1/account")
+	account.Stack = append(account.Stack, trace("account"))
+	account.Get("/:number<digits{10}>/transactions", noop).Named("account.transactions")
+	account.Patch("/deposit", noop, trace("idempotent"))
+	rt.Get("/files/*path", noop).Named("files")
+
+	t.Run("Should build the URL of a named route", func(t *testing.T) {
+
+		path, err := rt.URL("account.transactions", map[string]string{"number": "0123456789"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != "/v1/account/0123456789/transactions" {
+			t.Fatalf("unexpected path: got %v want %v", path, "/v1/account/0123456789/transactions")
+		}
+
+		path, err = rt.URL("files", map[string]string{"path": "kyc/passport one.png"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path != "/files/kyc/passport%20one.png" {
+			t.Fatalf("unexpected path: got %v want %v", path, "/files/kyc/passport%20one.png")
+		}
+
+	})
+
+	t.Run("Should reject params that do not satisfy their constraint", func(t *testing.T) {
+
+		if _, err := rt.URL("account.transactions", map[string]string{"number": "12"}); err == nil {
+			t.Fatal("expected error but got none")
+		}
+		if _, err := rt.URL("account.transactions", nil); err == nil {
+			t.Fatal("expected error but got none")
+		}
+		if _, err := rt.URL("unknown", nil); err == nil {
+			t.Fatal("expected error but got none")
+		}
+
+	})
+
+	t.Run("Should list every route with its middleware", func(t *testing.T) {
+
+		infos := rt.List()
+		if len(infos) != 3 {
+			t.Fatalf("unexpected number of routes: got %v want %v", len(infos), 3)
+		}
+		deposit := infos[2]
+		if deposit.Method != "PATCH" || deposit.Pattern != "/v1/account/deposit" {
+			t.Fatalf("unexpected route: got %v", deposit)
+		}
+		if len(deposit.Middleware) != 2 {
+			t.Fatalf("unexpected middleware: got %v", deposit.Middleware)
+		}
+		if infos[1].Name != "account.transactions" {
+			t.Fatalf("unexpected name: got %v want %v", infos[1].Name, "account.transactions")
+		}
+
+		if len(account.List()) != 2 {
+			t.Fatalf("unexpected number of routes for nested router: got %v want %v", len(account.List()), 2)
+		}
+
+	})
+
+	t.Run("Should list routes of the same pattern in a stable order", func(t *testing.T) {
+
+		rt := New()
+		rt.Delete("/v1/user/:id", noop)
+		rt.Version("2").Patch("/v1/user/:id", noop)
+		rt.Get("/v1/user/:id", noop)
+		rt.Version("1").Patch("/v1/user/:id", noop)
+		rt.Put("/v1/user/:id", noop)
+
+		want := "GET,PUT,PATCH 1,PATCH 2,DELETE"
+		for i := 0; i < 20; i++ {
+			got := []string{}
+			for _, info := range rt.List() {
+				got = append(got, strings.TrimSpace(info.Method+" "+info.Version))
+			}
+			if strings.Join(got, ",") != want {
+				t.Fatalf("unexpected order: got %v want %v", strings.Join(got, ","), want)
+			}
+		}
+
+	})
+
+	t.Run("Should panic when a name is taken", func(t *testing.T) {
+
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected a panic")
+			}
+		}()
+		rt.Get("/other", func(http.ResponseWriter, *http.Request) {}).Named("files")
+
+	})
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Options registers a route with the OPTIONS HTTP method.
+// The optional middleware only runs for this route, after the global and router stacks.
+// The returned route can be named with route.Named for reverse URL building.
+func Options(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return Default.Options(path, handler, m...)
+}
+
+// Options registers a route on the router with the OPTIONS HTTP method
+func (r *Router) Options(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return r.handle(options, path, handler, m...)
+}
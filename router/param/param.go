@@ -2,8 +2,11 @@ package param
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"reflect"
 
+	"github.com/opensaucerer/barf/helper"
 	"github.com/opensaucerer/barf/typing"
 )
 
@@ -27,8 +30,42 @@ func (p P) JSON() (map[string]string, error) {
 	return data, err
 }
 
-// Format formats the request params into the given interface v which must be a pointer.
-// It returns an error if the params is not a valid JSON.
+/*
+Format formats the request params into the given interface v which must be a pointer.
+
+When v points to a struct, each param is converted into the type of the field named by its `param` tag, falling back to its `json` tag and then the field name.
+This allows constrained params such as :id<int> to be bound directly into an int field.
+
+	type Lookup struct {
+		Number string `param:"number"`
+		Page   int    `param:"page"`
+	}
+
+It returns an error if a param cannot be converted into the type of its field.
+*/
 func (p P) Format(v interface{}) error {
-	return json.Unmarshal(p, v)
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return json.Unmarshal(p, v)
+	}
+	params, err := p.JSON()
+	if err != nil {
+		return err
+	}
+	rv = rv.Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := helper.Name(f, "param")
+		value, ok := params[name]
+		if name == "" || !ok {
+			continue
+		}
+		if err := helper.Assign(rv.Field(i), value); err != nil {
+			return fmt.Errorf("invalid path param %s: %w", name, err)
+		}
+	}
+	return nil
 }
@@ -1,13 +1,19 @@
 package router
 
-import "net/http"
-
-// Patch registers a route with the PATCH HTTP method
-func Patch(path string, handler func(http.ResponseWriter, *http.Request)) {
-	route := &Route{
-		Path:    path,
-		Method:  patch,
-		Handler: handler,
-	}
-	route.Register()
+import (
+	"net/http"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Patch registers a route with the PATCH HTTP method.
+// The optional middleware only runs for this route, after the global and router stacks.
+// The returned route can be named with route.Named for reverse URL building.
+func Patch(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return Default.Patch(path, handler, m...)
+}
+
+// Patch registers a route on the router with the PATCH HTTP method
+func (r *Router) Patch(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return r.handle(patch, path, handler, m...)
 }
@@ -1,13 +1,19 @@
 package router
 
-import "net/http"
-
-// Post registers a route with the POST HTTP method
-func Post(path string, handler func(http.ResponseWriter, *http.Request)) {
-	route := &Route{
-		Path:    path,
-		Method:  post,
-		Handler: handler,
-	}
-	route.Register()
+import (
+	"net/http"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Post registers a route with the POST HTTP method.
+// The optional middleware only runs for this route, after the global and router stacks.
+// The returned route can be named with route.Named for reverse URL building.
+func Post(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return Default.Post(path, handler, m...)
+}
+
+// Post registers a route on the router with the POST HTTP method
+func (r *Router) Post(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return r.handle(post, path, handler, m...)
 }
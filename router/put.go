@@ -1,13 +1,19 @@
 package router
 
-import "net/http"
-
-// Put registers a route with the PUT HTTP method
-func Put(path string, handler func(http.ResponseWriter, *http.Request)) {
-	route := &Route{
-		Path:    path,
-		Method:  put,
-		Handler: handler,
-	}
-	route.Register()
+import (
+	"net/http"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Put registers a route with the PUT HTTP method.
+// The optional middleware only runs for this route, after the global and router stacks.
+// The returned route can be named with route.Named for reverse URL building.
+func Put(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return Default.Put(path, handler, m...)
+}
+
+// Put registers a route on the router with the PUT HTTP method
+func (r *Router) Put(path string, handler func(http.ResponseWriter, *http.Request), m ...typing.Middleware) *Route {
+	return r.handle(put, path, handler, m...)
 }
@@ -3,29 +3,65 @@ package query
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"reflect"
+
+	"github.com/opensaucerer/barf/helper"
+	"github.com/opensaucerer/barf/validate"
 )
 
-type Q []byte
+// Q holds the values of the request query
+type Q struct {
+	values url.Values
+}
 
-func Query(r *http.Request) []byte {
-	q := make(map[string]string)
-	for k, v := range r.URL.Query() {
-		q[k] = v[0]
-	}
-	json, _ := json.Marshal(q)
-	return json
+func Query(r *http.Request) Q {
+	return Q{values: r.URL.Query()}
 }
 
-// JSON formats the request query as map[string]string.
-// It returns an error if the query is not a valid JSON.
+// Values returns every value of every key of the request query
+func (q Q) Values() url.Values {
+	return q.values
+}
+
+// JSON formats the request query as map[string]string keeping the first value of each key.
 func (q Q) JSON() (map[string]string, error) {
-	var data map[string]string
-	err := json.Unmarshal(q, &data)
-	return data, err
+	data := make(map[string]string, len(q.values))
+	for k, v := range q.values {
+		data[k] = v[0]
+	}
+	return data, nil
 }
 
-// Format formats the request query into the given interface. v must be a pointer.
-// It returns an error if the query is not a valid JSON.
+/*
+Format formats the request query into the given interface. v must be a pointer.
+
+When v points to a struct, each field is named by its `query` tag, falling back to its `json` tag and then the field name,
+and its values are converted into the type of the field. Slice fields receive every value of a repeated key such as ?type=1&type=2.
+Ints, uints, floats, bools, strings, time.Time, pointers and types implementing encoding.TextUnmarshaler are supported.
+
+	type Filter struct {
+		Limit int       `query:"limit,default=20"`
+		Types []string  `query:"type"`
+		From  time.Time `query:"from"`
+	}
+
+It returns typing.FieldErrors listing every field whose value could not be converted.
+Once bound, the struct is validated against the rules of its `validate` tags and the rejected fields are returned as typing.FieldErrors too.
+For any other v, the first value of each key is decoded with encoding/json.
+*/
 func (q Q) Format(v interface{}) error {
-	return json.Unmarshal(q, v)
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		data, _ := q.JSON()
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(raw, v)
+	}
+	if err := helper.Bind(v, q.values, "query"); err != nil {
+		return err
+	}
+	return validate.Struct(v)
 }
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// level is a custom type binding itself from text
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+// filter mirrors the filters of the transactions route of the zeina app
+type filter struct {
+	Number string    `json:"number"`
+	Limit  int       `query:"limit,default=20"`
+	Types  []int     `query:"type"`
+	Before time.Time `query:"before"`
+	Sign   *bool     `query:"signed"`
+	Level  level     `query:"level"`
+	Kinds  []string  `query:"kind,default=credit|debit"`
+	Cursor []byte    `query:"cursor"`
+}
+
+// go test -v -run TestQueryUnit ./...
+func TestQueryUnit(t *testing.T) {
+
+	t.Run("Should bind typed and repeated values", func(t *testing.T) {
+
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/transactions?number=0123456789&limit=50&type=1&type=2&before=2026-10-01&signed=true&level=high", nil)
+		var data filter
+		if err := Query(r).Format(&data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if data.Number != "0123456789" || data.Limit != 50 || data.Level != 2 {
+			t.Fatalf("unexpected data: got %+v", data)
+		}
+		if len(data.Types) != 2 || data.Types[0] != 1 || data.Types[1] != 2 {
+			t.Fatalf("unexpected types: got %v want %v", data.Types, []int{1, 2})
+		}
+		if !data.Before.Equal(time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected time: got %v", data.Before)
+		}
+		if data.Sign == nil || !*data.Sign {
+			t.Fatalf("unexpected pointer: got %v", data.Sign)
+		}
+
+	})
+
+	t.Run("Should bind byte slices from a single value", func(t *testing.T) {
+
+		var data filter
+		if err := Query(httptest.NewRequest(http.MethodGet, "/v1/account/transactions?cursor=tx-0042&cursor=tx-0043", nil)).Format(&data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data.Cursor) != "tx-0042" {
+			t.Fatalf("unexpected cursor: got %q want %q", data.Cursor, "tx-0042")
+		}
+
+	})
+
+	t.Run("Should fall back to default values", func(t *testing.T) {
+
+		var data filter
+		if err := Query(httptest.NewRequest(http.MethodGet, "/v1/account/transactions", nil)).Format(&data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if data.Limit != 20 {
+			t.Fatalf("unexpected limit: got %v want %v", data.Limit, 20)
+		}
+		if len(data.Kinds) != 2 || data.Kinds[1] != "debit" {
+			t.Fatalf("unexpected kinds: got %v want %v", data.Kinds, []string{"credit", "debit"})
+		}
+
+	})
+
+	t.Run("Should report every field that cannot be converted", func(t *testing.T) {
+
+		var data filter
+		err := Query(httptest.NewRequest(http.MethodGet, "/v1/account/transactions?limit=many&type=1&type=x&level=medium", nil)).Format(&data)
+		var fields typing.FieldErrors
+		if !errors.As(err, &fields) {
+			t.Fatalf("unexpected error: got %v", err)
+		}
+		if len(fields) != 3 || fields[0].Field != "limit" || fields[1].Field != "type" || fields[2].Field != "level" {
+			t.Fatalf("unexpected field errors: got %v", fields)
+		}
+
+	})
+
+	t.Run("Should decode the first values into maps", func(t *testing.T) {
+
+		var data map[string]string
+		if err := Query(httptest.NewRequest(http.MethodGet, "/?type=1&type=2", nil)).Format(&data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if data["type"] != "1" {
+			t.Fatalf("unexpected value: got %v want %v", data["type"], "1")
+		}
+
+	})
+}
@@ -1,19 +1,20 @@
 package router
 
-import (
-	"net/http"
-	"regexp"
-)
+import logger "github.com/opensaucerer/barf/log"
 
-// Register registers a route in the router table
+// Register registers a route in the router table.
+// It panics with a *Conflict if the route duplicates or is ambiguous with a registered route.
+// Shadowed routes are logged as warnings unless the root router is strict, in which case they panic too.
 func (r *Route) Register() {
 	// remove preceding and trailing slashes
-	r.Path = regexp.MustCompile("^/+|/+$").ReplaceAllString(r.Path, "")
-	if r.Path == "" {
-		r.Path = "/"
+	r.Path = trim(r.Path)
+	c := r.table().insert(r)
+	if c == nil {
+		return
 	}
-	if table[r.Path] == nil {
-		table[r.Path] = make(map[string]func(http.ResponseWriter, *http.Request))
+	if c.Kind == Shadowed && !r.strict() {
+		logger.Warn(c.Error())
+		return
 	}
-	table[r.Path][r.Method] = r.Handler
+	panic(c)
 }
@@ -2,6 +2,7 @@ package router
 
 import (
 	"net/http"
+	"sync/atomic"
 
 	"github.com/opensaucerer/barf/typing"
 )
@@ -12,17 +13,91 @@ type Route struct {
 	Handler func(http.ResponseWriter, *http.Request)
 	Query   map[string]string
 	Params  map[string]string
+	// Stack is the middleware that only runs for this route
+	Stack []typing.Middleware
+	// Router is the router the route was registered on, if any
+	Router *Router
+	// Name is the name given to the route with route.Named, if any
+	Name string
+	// Host is the host pattern the route was registered for, or the request host when looking a route up
+	Host string
+	// Version is the API version the route was registered for, or the version requested when looking a route up
+	Version string
+	// Pattern is the pattern of the registered route found when looking a route up i.e /v1/account/:number
+	Pattern string
+	// composed holds the *composed middleware chain of a registered route
+	composed atomic.Value
 }
 
 type Router struct {
 	Entry  string
 	Routes []*Route
 	Stack  []typing.Middleware
-}
-
-func (r Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// parent is the router this router was framed from, if any
+	parent *Router
+	// Strict makes route registration panic on shadowed routes instead of logging a warning. It only applies to root routers.
+	Strict bool
+	// tree holds the routes registered on a root router and every router framed from it
+	tree *tree
+	// hosts holds the routes registered on a root router for host patterns
+	hosts []*host
+	// host is the host pattern a router created with Host is bound to
+	host *host
+	// Versioning configures API version negotiation. It only applies to root routers.
+	Versioning *typing.Versioning
+	// api is the API version a router created with Version registers its routes for
+	api string
 }
 
 type Hippocampus interface {
 	ServeHTTP(w http.ResponseWriter, r *http.Request)
 }
+
+// composed is the middleware chain of a route along with the stacks it was composed from
+type composed struct {
+	serve  func(http.ResponseWriter, *http.Request)
+	stacks [][]typing.Middleware
+}
+
+// chain returns the route handler wrapped in its own middleware stack and that of every router it was registered on.
+// The stack of the outermost router runs first and the route stack runs last.
+// The chain is composed once and composed again only when any of the stacks is replaced or grows.
+func (r *Route) chain() func(http.ResponseWriter, *http.Request) {
+	if c, ok := r.composed.Load().(*composed); ok && c.current(r) {
+		return c.serve
+	}
+	c := &composed{stacks: [][]typing.Middleware{r.Stack}}
+	var h http.Handler = http.HandlerFunc(r.Handler)
+	for i := range r.Stack {
+		h = r.Stack[len(r.Stack)-1-i](h)
+	}
+	for rt := r.Router; rt != nil; rt = rt.parent {
+		for i := range rt.Stack {
+			h = rt.Stack[len(rt.Stack)-1-i](h)
+		}
+		c.stacks = append(c.stacks, rt.Stack)
+	}
+	c.serve = h.ServeHTTP
+	r.composed.Store(c)
+	return c.serve
+}
+
+// current returns true if the stacks of the given route and its routers are still the ones c was composed from
+func (c *composed) current(r *Route) bool {
+	if !same(c.stacks[0], r.Stack) {
+		return false
+	}
+	i := 1
+	for rt := r.Router; rt != nil; rt = rt.parent {
+		if i == len(c.stacks) || !same(c.stacks[i], rt.Stack) {
+			return false
+		}
+		i++
+	}
+	return i == len(c.stacks)
+}
+
+// same returns true if the given stacks share their length and their backing array
+func same(a, b []typing.Middleware) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
@@ -1,5 +1,44 @@
 package router
 
-import "net/http"
+import "github.com/opensaucerer/barf/typing"
 
-var table = map[string]map[string]func(http.ResponseWriter, *http.Request){}
+// Default is the root router holding the routes registered with the package level functions such as barf.Get
+var Default = New()
+
+// New creates a root router with its own route table. Every barf app owns one.
+func New() *Router {
+	return &Router{
+		Entry: "/",
+		Stack: []typing.Middleware{},
+		tree:  newTree(),
+	}
+}
+
+// root returns the root router r was framed from
+func (r *Router) root() *Router {
+	for r.parent != nil {
+		r = r.parent
+	}
+	return r
+}
+
+// strict returns true if the root router of the route reports shadowed routes by panicking
+func (r *Route) strict() bool {
+	return r.root().Strict
+}
+
+// root returns the root router the route is registered on or looked up from
+func (r *Route) root() *Router {
+	if r.Router == nil {
+		return Default
+	}
+	return r.Router.root()
+}
+
+// table returns the route table the given route is registered on
+func (r *Route) table() *tree {
+	if h := r.Router.hostname(); h != nil {
+		return h.tree
+	}
+	return r.root().tree
+}
@@ -0,0 +1,299 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+)
+
+// kind is the type of a node in the routing tree
+type kind uint8
+
+const (
+	// staticNode matches its prefix byte for byte
+	staticNode kind = iota
+	// paramNode matches exactly one non-empty path segment
+	paramNode
+	// catchAllNode matches the remainder of the path
+	catchAllNode
+)
+
+// node is a single node of the compressed radix tree used to route requests.
+// Children are matched in priority order: static, then param, then catch-all.
+type node struct {
+	kind kind
+	// prefix is the path fragment matched by a static node
+	prefix string
+	// name is the name of the parameter captured by a param or catch-all node
+	name string
+	// constraint restricts the segments matched by a param node, if any
+	constraint *constraint
+	// indices holds the first byte of every static child, in the same order as statics
+	indices string
+	statics []*node
+	params  []*node
+	// catchAlls holds the catch-all children, one per name
+	catchAlls []*node
+	// pattern is the normalized pattern that ends at this node
+	pattern string
+	// names holds the names of all parameters captured on the way to this node
+	names []string
+	// routes holds the registered routes keyed by method and then by API version, "" for unversioned routes
+	routes map[string]map[string]*Route
+}
+
+// tree is a compressed radix tree holding every registered route
+type tree struct {
+	root *node
+	// names holds the named routes
+	names map[string]*Route
+}
+
+// newTree creates an empty routing tree
+func newTree() *tree {
+	return &tree{root: &node{kind: staticNode}, names: map[string]*Route{}}
+}
+
+// trim removes preceding and trailing slashes from the given path
+func trim(path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// key returns the normalized form of the given path as stored in the tree
+func key(path string) string {
+	return "/" + strings.Trim(path, "/")
+}
+
+// insert adds the given route to the tree.
+// Duplicate and ambiguous routes are not inserted and returned as a conflict.
+// Shadowed routes are inserted but still returned as a conflict.
+func (t *tree) insert(r *Route) *Conflict {
+	shadowed := t.clash(r)
+	if shadowed != nil && shadowed.Kind == Ambiguous {
+		return shadowed
+	}
+	n := t.root
+	names := []string{}
+	static := ""
+	segments := strings.Split(strings.Trim(r.Path, "/"), "/")
+	for i, segment := range segments {
+		static += "/"
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			n = n.addStatic(static)
+			static = ""
+			name, c := parameter(segment[1:])
+			n = n.addParam(name, c)
+			names = append(names, name)
+		case strings.HasPrefix(segment, "*"):
+			if i != len(segments)-1 {
+				panic(fmt.Sprintf("barf: catch-all segment %s must be the last segment in path /%s", segment, trim(r.Path)))
+			}
+			n = n.addStatic(static)
+			static = ""
+			n = n.addCatchAll(segment[1:])
+			names = append(names, segment[1:])
+		default:
+			static += segment
+		}
+	}
+	n = n.addStatic(static)
+	if n.routes[r.Method][r.Version] != nil {
+		return conflict(Duplicate, r, n.pattern)
+	}
+	if n.routes == nil {
+		n.routes = map[string]map[string]*Route{}
+	}
+	if n.routes[r.Method] == nil {
+		n.routes[r.Method] = map[string]*Route{}
+	}
+	n.pattern = key(r.Path)
+	n.names = names
+	n.routes[r.Method][r.Version] = r
+	return shadowed
+}
+
+// route returns the route registered at n for the given method and requested API version.
+// Without a requested version, the route of the default version is preferred over the unversioned route.
+// Requested versions that are not registered at n fall back to the unversioned route, if any.
+func (n *node) route(method, version, fallback string) *Route {
+	versions := n.routes[method]
+	if versions == nil {
+		// mounted handlers serve every method
+		versions = n.routes[wildcard]
+	}
+	if versions == nil {
+		return nil
+	}
+	if version == "" {
+		version = fallback
+	}
+	if route := versions[version]; route != nil {
+		return route
+	}
+	return versions[""]
+}
+
+// lookup returns the node matching the given path for which ok returns true, along with the captured parameters
+func (t *tree) lookup(path string, ok func(*node) bool) (*node, map[string]string) {
+	n, values := t.root.find(key(path), ok, make([]string, 0, 4))
+	if n == nil {
+		return nil, nil
+	}
+	params := make(map[string]string, len(n.names))
+	for i, name := range n.names {
+		params[name] = values[i]
+	}
+	return n, params
+}
+
+// addStatic inserts the given static fragment below n and returns the node it ends at
+func (n *node) addStatic(s string) *node {
+	if s == "" {
+		return n
+	}
+	for i, child := range n.statics {
+		if child.prefix[0] != s[0] {
+			continue
+		}
+		l := common(child.prefix, s)
+		if l < len(child.prefix) {
+			// split the child at the end of the common prefix
+			tail := *child
+			tail.prefix = child.prefix[l:]
+			child = &node{
+				kind:    staticNode,
+				prefix:  child.prefix[:l],
+				indices: tail.prefix[:1],
+				statics: []*node{&tail},
+			}
+			n.statics[i] = child
+		}
+		return child.addStatic(s[l:])
+	}
+	child := &node{kind: staticNode, prefix: s}
+	n.indices += s[:1]
+	n.statics = append(n.statics, child)
+	return child
+}
+
+// addParam returns the param child of n with the given name and constraint, creating it if needed.
+// Constrained params are kept ahead of unconstrained ones so they are tried first.
+func (n *node) addParam(name string, c *constraint) *node {
+	at := len(n.params)
+	for i, child := range n.params {
+		if child.name == name && child.constraint.String() == c.String() {
+			return child
+		}
+		if c != nil && child.constraint == nil && i < at {
+			at = i
+		}
+	}
+	child := &node{kind: paramNode, name: name, constraint: c}
+	n.params = append(n.params[:at], append([]*node{child}, n.params[at:]...)...)
+	return child
+}
+
+// addCatchAll returns the catch-all child of n with the given name, creating it if needed
+func (n *node) addCatchAll(name string) *node {
+	for _, child := range n.catchAlls {
+		if child.name == name {
+			return child
+		}
+	}
+	child := &node{kind: catchAllNode, name: name}
+	n.catchAlls = append(n.catchAlls, child)
+	return child
+}
+
+// find walks the tree below n looking for a node matching path for which ok returns true.
+// It backtracks whenever a branch fails so that lower priority children still get a chance.
+func (n *node) find(path string, ok func(*node) bool, values []string) (*node, []string) {
+	switch n.kind {
+	case staticNode:
+		if !strings.HasPrefix(path, n.prefix) {
+			// a catch-all also matches its bare prefix i.e /files for /files/*path
+			if n.prefix == path+"/" {
+				for _, child := range n.catchAlls {
+					if m, v := child.find("", ok, values); m != nil {
+						return m, v
+					}
+				}
+			}
+			return nil, values
+		}
+		path = path[len(n.prefix):]
+	case paramNode:
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end == 0 || (n.constraint != nil && !n.constraint.match(path[:end])) {
+			return nil, values
+		}
+		values = append(values, path[:end])
+		path = path[end:]
+	case catchAllNode:
+		if ok(n) {
+			return n, append(values, path)
+		}
+		return nil, values
+	}
+
+	if path == "" && ok(n) {
+		return n, values
+	}
+
+	depth := len(values)
+
+	// static children have the highest priority
+	first := byte('/')
+	if path != "" {
+		first = path[0]
+	}
+	if i := strings.IndexByte(n.indices, first); i >= 0 {
+		if m, v := n.statics[i].find(path, ok, values); m != nil {
+			return m, v
+		}
+	}
+
+	if path == "" {
+		// the root catch-all matches an empty remainder i.e / for /*path
+		if strings.HasSuffix(n.prefix, "/") {
+			for _, child := range n.catchAlls {
+				if m, v := child.find("", ok, values[:depth]); m != nil {
+					return m, v
+				}
+			}
+		}
+		return nil, values[:depth]
+	}
+
+	// params come next, constrained ones first
+	for _, child := range n.params {
+		if m, v := child.find(path, ok, values[:depth]); m != nil {
+			return m, v
+		}
+	}
+
+	// and catch-all has the lowest priority
+	for _, child := range n.catchAlls {
+		if m, v := child.find(path, ok, values[:depth]); m != nil {
+			return m, v
+		}
+	}
+
+	return nil, values[:depth]
+}
+
+// common returns the length of the longest common prefix of a and b
+func common(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
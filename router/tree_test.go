@@ -0,0 +1,391 @@
+package router
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// routes mirrors the shape of the routes registered by the zeina app
+var routes = []struct {
+	method string
+	path   string
+}{
+	{get, "/"},
+	{post, "/v1/user/register"},
+	{get, "/v1/user/:key"},
+	{post, "/v1/account/create"},
+	{get, "/v1/account/search"},
+	{patch, "/v1/account/deposit"},
+	{patch, "/v1/account/lock"},
+	{patch, "/v1/account/unlock"},
+	{patch, "/v1/account/withdraw"},
+	{get, "/v1/account/transactions"},
+	{get, "/v1/account/:number"},
+	{get, "/v1/account/:number/transactions"},
+	{get, "/v1/account/:number/transactions/:session"},
+	{get, "/v1/transaction"},
+	{get, "/v1/transaction/:session"},
+	{get, "/v1/files/*path"},
+}
+
+func noop(http.ResponseWriter, *http.Request) {}
+
+// build creates a tree holding all of the test routes
+func build() *tree {
+	t := newTree()
+	for _, r := range routes {
+		t.insert(&Route{Path: trim(r.path), Method: r.method, Handler: noop})
+	}
+	return t
+}
+
+// match returns a predicate matching nodes with a route for the given method
+func match(method string) func(*node) bool {
+	return func(n *node) bool {
+		return n.route(method, "", "") != nil
+	}
+}
+
+// go test -v -run TestTreeUnit ./...
+func TestTreeUnit(t *testing.T) {
+
+	tr := build()
+
+	t.Run("Should match static routes", func(t *testing.T) {
+
+		for _, r := range routes {
+			if strings.ContainsAny(r.path, ":*") {
+				continue
+			}
+			n, _ := tr.lookup(r.path, match(r.method))
+			if n == nil {
+				t.Fatalf("route not found: %s %s", r.method, r.path)
+			}
+			if n.pattern != key(r.path) {
+				t.Fatalf("unexpected pattern: got %v want %v", n.pattern, key(r.path))
+			}
+		}
+
+	})
+
+	t.Run("Should prefer static segments over params", func(t *testing.T) {
+
+		n, params := tr.lookup("/v1/account/transactions", match(get))
+		if n == nil || n.pattern != "/v1/account/transactions" {
+			t.Fatalf("unexpected match: got %v", n)
+		}
+		if len(params) != 0 {
+			t.Fatalf("unexpected params: got %v", params)
+		}
+
+	})
+
+	t.Run("Should fall back to params when the static branch has no handler for the method", func(t *testing.T) {
+
+		n, params := tr.lookup("/v1/account/deposit", match(get))
+		if n == nil || n.pattern != "/v1/account/:number" {
+			t.Fatalf("unexpected match: got %v", n)
+		}
+		if params["number"] != "deposit" {
+			t.Fatalf("unexpected param: got %v want %v", params["number"], "deposit")
+		}
+
+	})
+
+	t.Run("Should capture multiple params", func(t *testing.T) {
+
+		n, params := tr.lookup("/v1/account/0123456789/transactions/abc/", match(get))
+		if n == nil || n.pattern != "/v1/account/:number/transactions/:session" {
+			t.Fatalf("unexpected match: got %v", n)
+		}
+		if params["number"] != "0123456789" || params["session"] != "abc" {
+			t.Fatalf("unexpected params: got %v", params)
+		}
+
+	})
+
+	t.Run("Should capture the remainder of the path in a catch-all", func(t *testing.T) {
+
+		n, params := tr.lookup("/v1/files/kyc/passport.png", match(get))
+		if n == nil || n.pattern != "/v1/files/*path" {
+			t.Fatalf("unexpected match: got %v", n)
+		}
+		if params["path"] != "kyc/passport.png" {
+			t.Fatalf("unexpected param: got %v want %v", params["path"], "kyc/passport.png")
+		}
+
+		n, params = tr.lookup("/v1/files", match(get))
+		if n == nil || params["path"] != "" {
+			t.Fatalf("catch-all should match its bare prefix: got %v %v", n, params)
+		}
+
+	})
+
+	t.Run("Should only match params satisfying their constraint", func(t *testing.T) {
+
+		ct := newTree()
+		ct.insert(&Route{Path: "v1/account/:slug", Method: get, Handler: noop})
+		ct.insert(&Route{Path: "v1/account/:number<digits{10}>", Method: get, Handler: noop})
+		ct.insert(&Route{Path: "v1/transaction/:id<int>", Method: get, Handler: noop})
+		ct.insert(&Route{Path: "v1/transaction/:code<[a-f]{4}>", Method: get, Handler: noop})
+
+		cases := []struct {
+			path    string
+			pattern string
+		}{
+			{"/v1/account/0123456789", "/v1/account/:number<digits{10}>"},
+			{"/v1/account/012345678", "/v1/account/:slug"},
+			{"/v1/transaction/-42", "/v1/transaction/:id<int>"},
+			{"/v1/transaction/beef", "/v1/transaction/:code<[a-f]{4}>"},
+			{"/v1/transaction/beefy", ""},
+		}
+		for _, c := range cases {
+			n, _ := ct.lookup(c.path, match(get))
+			if c.pattern == "" {
+				if n != nil {
+					t.Fatalf("unexpected match for %s: got %v", c.path, n.pattern)
+				}
+				continue
+			}
+			if n == nil || n.pattern != c.pattern {
+				t.Fatalf("unexpected match for %s: got %v want %v", c.path, n, c.pattern)
+			}
+		}
+
+	})
+
+	t.Run("Should not match unknown paths", func(t *testing.T) {
+
+		for _, path := range []string{"/v1", "/v1/account/123/unknown", "/v2/account/create", "/v1/user"} {
+			if n, _ := tr.lookup(path, match(get)); n != nil {
+				t.Fatalf("unexpected match for %s: got %v", path, n.pattern)
+			}
+		}
+
+	})
+}
+
+// legacy is the linear scan router.Func used before the radix tree. It is kept here for benchmarking.
+func legacy(table map[string]map[string]func(http.ResponseWriter, *http.Request), r *Route) {
+	r.Path = regexp.MustCompile("^/+|/+$").ReplaceAllString(r.Path, "")
+	if r.Path == "" {
+		r.Path = "/"
+	}
+	if table[r.Path] != nil && table[r.Path][r.Method] != nil {
+		r.Handler = table[r.Path][r.Method]
+		return
+	}
+	paths := strings.Split(r.Path, "/")
+TLoop:
+	for path, methods := range table {
+		variables := strings.Split(path, "/")
+		if len(paths) == len(variables) && methods[r.Method] != nil {
+			match := true
+		VLoop:
+			for i, variable := range variables {
+				if variable != paths[i] && (len(variable) == 0 || variable[0] != ':') {
+					match = false
+					break VLoop
+				}
+			}
+			if match {
+				r.Handler = methods[r.Method]
+				r.Params = Params(r.Path, path)
+				break TLoop
+			}
+		}
+	}
+}
+
+// legacyTable builds the map based table used by the legacy router
+func legacyTable() map[string]map[string]func(http.ResponseWriter, *http.Request) {
+	table := map[string]map[string]func(http.ResponseWriter, *http.Request){}
+	for _, r := range routes {
+		path := trim(r.path)
+		if table[path] == nil {
+			table[path] = map[string]func(http.ResponseWriter, *http.Request){}
+		}
+		table[path][r.method] = noop
+	}
+	return table
+}
+
+func benchmarkTree(b *testing.B, method, path string) {
+	tr := build()
+	ok := match(method)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if n, _ := tr.lookup(path, ok); n == nil {
+			b.Fatalf("route not found: %s %s", method, path)
+		}
+	}
+}
+
+func benchmarkLegacy(b *testing.B, method, path string) {
+	table := legacyTable()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := Route{Path: path, Method: method}
+		legacy(table, &r)
+		if r.Handler == nil {
+			b.Fatalf("route not found: %s %s", method, path)
+		}
+	}
+}
+
+// go test -run XXX -bench . ./router
+func BenchmarkTreeStatic(b *testing.B) {
+	benchmarkTree(b, get, "/v1/account/transactions")
+}
+
+func BenchmarkLegacyStatic(b *testing.B) {
+	benchmarkLegacy(b, get, "/v1/account/transactions")
+}
+
+func BenchmarkTreeParam(b *testing.B) {
+	benchmarkTree(b, get, "/v1/account/0123456789/transactions/abc")
+}
+
+func BenchmarkLegacyParam(b *testing.B) {
+	benchmarkLegacy(b, get, "/v1/account/0123456789/transactions/abc")
+}
+
+// go test -v -run TestConflictUnit ./...
+func TestConflictUnit(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		existing string
+		path     string
+		kind     ConflictKind
+	}{
+		{"Should detect duplicate routes", "/v1/account/create", "v1/account/create/", Duplicate},
+		{"Should detect differently named params at the same position", "/v1/:a", "/v1/:b", Ambiguous},
+		{"Should detect differently named params with the same constraint", "/v1/:a<int>/x", "/v1/:b<int>/x", Ambiguous},
+		{"Should detect differently named catch-all segments", "/files/*path", "/files/*rest", Ambiguous},
+		{"Should detect constrained params shadowed by a wider constraint", "/v1/account/:id<int>", "/v1/account/:number<digits{10}>", Shadowed},
+		{"Should detect constrained params shadowed before the same suffix", "/v1/account/:id<int>/lock", "/v1/account/:number<digits{10}>/lock", Shadowed},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			tr := newTree()
+			if err := tr.insert(&Route{Path: c.existing, Method: get, Handler: noop}); err != nil {
+				t.Fatalf("unexpected conflict: %v", err)
+			}
+			err := tr.insert(&Route{Path: c.path, Method: get, Handler: noop})
+			if err == nil {
+				t.Fatalf("expected a %s conflict but got none", c.kind)
+			}
+			if err.Kind != c.kind {
+				t.Fatalf("unexpected conflict: got %v want %v", err.Kind, c.kind)
+			}
+			if err.Existing != key(c.existing) {
+				t.Fatalf("unexpected existing route: got %v want %v", err.Existing, key(c.existing))
+			}
+
+		})
+	}
+
+	t.Run("Should allow distinct methods, names and constraints", func(t *testing.T) {
+
+		tr := newTree()
+		for _, r := range []*Route{
+			{Path: "v1/account/:number", Method: get},
+			{Path: "v1/account/:number", Method: patch},
+			{Path: "v1/account/:number<digits{10}>/transactions", Method: get},
+			{Path: "v1/account/:number/lock", Method: patch},
+			{Path: "v1/account/:number<digits{10}>/transactions/*rest", Method: get},
+		} {
+			r.Handler = noop
+			if err := tr.insert(r); err != nil {
+				t.Fatalf("unexpected conflict: %v", err)
+			}
+		}
+
+	})
+
+	t.Run("Should not report conflicts between routes with different suffixes", func(t *testing.T) {
+
+		for _, pair := range [][2]string{
+			{"/a/:id<int>/x", "/a/:n<digits>/y"},
+			{"/b/:x/foo", "/b/:y/bar"},
+			{"/c/:x/*path", "/c/:y/static"},
+			{"/d/:id<int>", "/d/:n<digits{25}>"},
+			{"/e/:id<uint>", "/e/:n<digits>"},
+		} {
+			tr := newTree()
+			if err := tr.insert(&Route{Path: pair[0], Method: get, Handler: noop}); err != nil {
+				t.Fatalf("unexpected conflict: %v", err)
+			}
+			if err := tr.insert(&Route{Path: pair[1], Method: get, Handler: noop}); err != nil {
+				t.Fatalf("unexpected conflict between %s and %s: %v", pair[0], pair[1], err)
+			}
+		}
+
+		tr := newTree()
+		tr.insert(&Route{Path: "/a/:id<int>/x", Method: get, Handler: noop})
+		tr.insert(&Route{Path: "/a/:n<digits>/y", Method: get, Handler: noop})
+		n, params := tr.lookup("/a/5/y", match(get))
+		if n == nil || params["n"] != "5" {
+			t.Fatalf("unexpected params: got %v want n=5", params)
+		}
+
+	})
+
+	t.Run("Should not report conflicts between routes of different methods", func(t *testing.T) {
+
+		tr := newTree()
+		for _, r := range []*Route{
+			{Path: "/v1/:a", Method: get},
+			{Path: "/v1/:b", Method: post},
+			{Path: "/v1/account/:id<int>", Method: get},
+			{Path: "/v1/account/:number<digits{10}>", Method: patch},
+			{Path: "/files/*path", Method: get},
+			{Path: "/files/*rest", Method: put},
+		} {
+			r.Handler = noop
+			if err := tr.insert(r); err != nil {
+				t.Fatalf("unexpected conflict: %v", err)
+			}
+		}
+
+		cases := []struct {
+			method string
+			path   string
+			name   string
+			value  string
+		}{
+			{get, "/v1/0123456789", "a", "0123456789"},
+			{post, "/v1/0123456789", "b", "0123456789"},
+			{patch, "/v1/account/0123456789", "number", "0123456789"},
+			{put, "/files/kyc/passport.png", "rest", "kyc/passport.png"},
+		}
+		for _, c := range cases {
+			n, params := tr.lookup(c.path, match(c.method))
+			if n == nil || params[c.name] != c.value {
+				t.Fatalf("unexpected params for %s %s: got %v want %s=%s", c.method, c.path, params, c.name, c.value)
+			}
+		}
+
+	})
+
+	t.Run("Should panic when registering a conflicting route", func(t *testing.T) {
+
+		rt := New()
+		rt.Get("/v1/account/create", noop)
+
+		defer func() {
+			if _, ok := recover().(*Conflict); !ok {
+				t.Fatal("expected a conflict panic")
+			}
+		}()
+		rt.Get("/v1/account/create", noop)
+
+	})
+}
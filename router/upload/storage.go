@@ -0,0 +1,33 @@
+package upload
+
+import (
+	"io"
+	"os"
+)
+
+// Temp stores uploaded files in a directory, the temporary directory of the OS if empty
+type Temp string
+
+// Save streams the given content into a new file of the directory and returns its path
+func (t Temp) Save(name string, content io.Reader) (string, error) {
+	f, err := os.CreateTemp(string(t), "barf-upload-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// Open opens the file at the given path
+func (t Temp) Open(key string) (io.ReadCloser, error) {
+	return os.Open(key)
+}
+
+// Remove removes the file at the given path
+func (t Temp) Remove(key string) error {
+	return os.Remove(key)
+}
@@ -0,0 +1,245 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/opensaucerer/barf/helper"
+	"github.com/opensaucerer/barf/router/body"
+	"github.com/opensaucerer/barf/typing"
+	"github.com/opensaucerer/barf/validate"
+)
+
+// sniffLen is the number of bytes content types are sniffed from
+const sniffLen = 512
+
+// File is a file received in a multipart request
+type File struct {
+	// Field is the name of the form field the file was sent in
+	Field string
+	// Name is the base name of the file as sent by the client
+	Name string
+	// ContentType is the content type sniffed from the content of the file
+	ContentType string
+	// Size is the number of bytes of the file
+	Size int64
+	// Key is the key the file is stored with, the path of the file for temporary files
+	Key string
+	// storage is where the file is stored
+	storage typing.Storage
+	// kept is true once the file should outlive the request
+	kept bool
+}
+
+// Open opens the stored file for reading
+func (f *File) Open() (io.ReadCloser, error) {
+	return f.storage.Open(f.Key)
+}
+
+// Keep prevents the file from being removed once the request is served, such as a file saved by a custom storage for good
+func (f *File) Keep() {
+	f.kept = true
+}
+
+// Form holds the values and files of a multipart request
+type Form struct {
+	// Values holds the values of the non-file fields
+	Values url.Values
+	// Files holds the files keyed by field name
+	Files map[string][]*File
+	// once guards RemoveAll
+	once sync.Once
+}
+
+// File returns the first file sent in the given field, or nil if none was
+func (f *Form) File(field string) *File {
+	if files := f.Files[field]; len(files) > 0 {
+		return files[0]
+	}
+	return nil
+}
+
+// Format binds the values of the form into the struct v points to, using the same `form` tags as barf.Request(r).Body().Format, and validates it
+func (f *Form) Format(v interface{}) error {
+	if err := helper.Bind(v, f.Values, "form"); err != nil {
+		return err
+	}
+	return validate.Struct(v)
+}
+
+// RemoveAll removes every stored file that was not kept. Requests served by barf call it once they are served.
+func (f *Form) RemoveAll() error {
+	var err error
+	f.once.Do(func() {
+		for _, files := range f.Files {
+			for _, file := range files {
+				if !file.kept {
+					if e := file.storage.Remove(file.Key); e != nil && err == nil {
+						err = e
+					}
+				}
+			}
+		}
+	})
+	return err
+}
+
+/*
+Files streams the files of a multipart/form-data request into storage as they are received, without buffering them in memory.
+
+Each file is sniffed for its content type, which must be one of the allowed content types, and must not exceed the max file size.
+All files and values together must not exceed the max total size. Files are removed again when any of them is rejected.
+
+It returns an error wrapping body.ErrUnsupportedMediaType for requests that are not multipart or files with a content type that is not allowed,
+which should be answered with a 415, and body.ErrRequestEntityTooLarge for files exceeding the limits, which should be answered with a 413.
+*/
+func Files(r *http.Request, opts typing.Uploads) (*Form, error) {
+	media, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || media != "multipart/form-data" {
+		return nil, fmt.Errorf("%w: expected multipart/form-data", body.ErrUnsupportedMediaType)
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	storage := opts.Storage
+	if storage == nil {
+		storage = Temp(opts.Dir)
+	}
+	form := &Form{Values: url.Values{}, Files: map[string][]*File{}}
+	// remove the files once the request is served by barf
+	if cleanup, ok := r.Context().Value(typing.CleanupCtxKey{}).(*[]func()); ok {
+		*cleanup = append(*cleanup, func() { form.RemoveAll() })
+	}
+	var total int64
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			form.RemoveAll()
+			return nil, err
+		}
+		field := part.FormName()
+		if field == "" {
+			part.Close()
+			continue
+		}
+		remaining := int64(-1)
+		if opts.MaxTotalSize > 0 {
+			remaining = opts.MaxTotalSize - total
+		}
+		if part.FileName() == "" {
+			value, err := read(part, remaining)
+			part.Close()
+			if err != nil {
+				form.RemoveAll()
+				return nil, fmt.Errorf("field %s: %w", field, err)
+			}
+			total += int64(len(value))
+			form.Values.Add(field, string(value))
+			continue
+		}
+		file, err := save(part, storage, opts, remaining)
+		part.Close()
+		if err != nil {
+			form.RemoveAll()
+			return nil, err
+		}
+		total += file.Size
+		form.Files[field] = append(form.Files[field], file)
+	}
+}
+
+// read reads a value of at most limit bytes, unless limit is negative
+func read(part io.Reader, limit int64) ([]byte, error) {
+	if limit < 0 {
+		return io.ReadAll(part)
+	}
+	value, err := io.ReadAll(io.LimitReader(part, limit+1))
+	if err == nil && int64(len(value)) > limit {
+		err = body.ErrRequestEntityTooLarge
+	}
+	return value, err
+}
+
+// filePart is a file part of a multipart request
+type filePart interface {
+	io.Reader
+	FormName() string
+	FileName() string
+}
+
+// save sniffs the content type of the given file part and streams it into storage
+func save(p filePart, storage typing.Storage, opts typing.Uploads, remaining int64) (*File, error) {
+	file := &File{Field: p.FormName(), Name: filepath.Base(p.FileName()), storage: storage}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(p, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+	file.ContentType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	if !allowed(file.ContentType, opts.Allowed) {
+		return nil, fmt.Errorf("file %s: %w: %s", file.Name, body.ErrUnsupportedMediaType, file.ContentType)
+	}
+	limit := opts.MaxFileSize
+	if limit <= 0 || (remaining >= 0 && remaining < limit) {
+		limit = remaining
+	}
+	counter := &counter{reader: io.MultiReader(bytes.NewReader(head), p), limit: limit}
+	file.Key, err = storage.Save(file.Name, counter)
+	if errors.Is(err, body.ErrRequestEntityTooLarge) || counter.exceeded {
+		if err == nil {
+			storage.Remove(file.Key)
+		}
+		return nil, fmt.Errorf("file %s: %w", file.Name, body.ErrRequestEntityTooLarge)
+	}
+	if err != nil {
+		return nil, err
+	}
+	file.Size = counter.n
+	return file, nil
+}
+
+// allowed returns true if the given content type matches any of the allowed content types or if none is given
+func allowed(contentType string, types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == contentType || t == "*/*" || (strings.HasSuffix(t, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// counter counts the bytes read from a reader and fails once more than limit bytes are read, unless limit is negative
+type counter struct {
+	reader   io.Reader
+	limit    int64
+	n        int64
+	exceeded bool
+}
+
+// Read reads from the underlying reader and returns body.ErrRequestEntityTooLarge once the limit is exceeded
+func (c *counter) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.n += int64(n)
+	if c.limit >= 0 && c.n > c.limit {
+		c.exceeded = true
+		return n, body.ErrRequestEntityTooLarge
+	}
+	return n, err
+}
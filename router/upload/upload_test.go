@@ -0,0 +1,145 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/opensaucerer/barf/router/body"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// memory is a storage keeping files in memory
+type memory map[string][]byte
+
+func (m memory) Save(name string, content io.Reader) (string, error) {
+	raw, err := io.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%d-%s", len(m), name)
+	m[key] = raw
+	return key, nil
+}
+
+func (m memory) Open(key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m[key])), nil
+}
+
+func (m memory) Remove(key string) error {
+	delete(m, key)
+	return nil
+}
+
+// png is the start of a PNG image
+var png = append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), bytes.Repeat([]byte{0}, 100)...)
+
+// kyc creates a multipart request uploading the given files along with an account number
+func kyc(files map[string][]byte) *http.Request {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("account_number", "0123456789")
+	for name, content := range files {
+		w, _ := mw.CreateFormFile("document", name)
+		w.Write(content)
+	}
+	mw.Close()
+	r := httptest.NewRequest(http.MethodPost, "/v1/account/kyc", &buf)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+// go test -v -run TestUploadUnit ./...
+func TestUploadUnit(t *testing.T) {
+
+	t.Run("Should stream files into the temporary directory and remove them", func(t *testing.T) {
+
+		dir := t.TempDir()
+		form, err := Files(kyc(map[string][]byte{"../passport.png": png}), typing.Uploads{Dir: dir})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var data struct {
+			Number string `form:"account_number" validate:"len=10"`
+		}
+		if err := form.Format(&data); err != nil || data.Number != "0123456789" {
+			t.Fatalf("unexpected values: got %v %v", data.Number, err)
+		}
+		file := form.File("document")
+		if file == nil || file.Name != "passport.png" || file.ContentType != "image/png" || file.Size != int64(len(png)) {
+			t.Fatalf("unexpected file: got %+v", file)
+		}
+		if _, err := os.Stat(file.Key); err != nil {
+			t.Fatalf("file was not stored: %v", err)
+		}
+		form.RemoveAll()
+		if _, err := os.Stat(file.Key); !os.IsNotExist(err) {
+			t.Fatalf("file was not removed: %v", err)
+		}
+
+	})
+
+	t.Run("Should save files into a custom storage", func(t *testing.T) {
+
+		storage := memory{}
+		form, err := Files(kyc(map[string][]byte{"passport.png": png}), typing.Uploads{Storage: storage, Allowed: []string{"image/*"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rc, _ := form.File("document").Open()
+		raw, _ := io.ReadAll(rc)
+		if !bytes.Equal(raw, png) {
+			t.Fatalf("unexpected content: got %v bytes want %v", len(raw), len(png))
+		}
+		form.File("document").Keep()
+		form.RemoveAll()
+		if len(storage) != 1 {
+			t.Fatalf("kept file was removed")
+		}
+
+	})
+
+	t.Run("Should reject content types that are not allowed", func(t *testing.T) {
+
+		storage := memory{}
+		_, err := Files(kyc(map[string][]byte{"passport.png": []byte("#!/bin/sh\nrm -rf /")}), typing.Uploads{Storage: storage, Allowed: []string{"image/png", "application/pdf"}})
+		if !errors.Is(err, body.ErrUnsupportedMediaType) {
+			t.Fatalf("unexpected error: got %v want %v", err, body.ErrUnsupportedMediaType)
+		}
+
+	})
+
+	t.Run("Should reject files over the limits and remove saved files", func(t *testing.T) {
+
+		storage := memory{}
+		_, err := Files(kyc(map[string][]byte{"passport.png": png}), typing.Uploads{Storage: storage, MaxFileSize: 64})
+		if !errors.Is(err, body.ErrRequestEntityTooLarge) {
+			t.Fatalf("unexpected error: got %v want %v", err, body.ErrRequestEntityTooLarge)
+		}
+
+		_, err = Files(kyc(map[string][]byte{"front.png": png, "back.png": png}), typing.Uploads{Storage: storage, MaxTotalSize: int64(len(png)) + 20})
+		if !errors.Is(err, body.ErrRequestEntityTooLarge) {
+			t.Fatalf("unexpected error: got %v want %v", err, body.ErrRequestEntityTooLarge)
+		}
+		if len(storage) != 0 {
+			t.Fatalf("unexpected stored files: got %v want %v", len(storage), 0)
+		}
+
+	})
+
+	t.Run("Should reject requests that are not multipart", func(t *testing.T) {
+
+		r := httptest.NewRequest(http.MethodPost, "/v1/account/kyc", bytes.NewReader(png))
+		r.Header.Set("Content-Type", "image/png")
+		if _, err := Files(r, typing.Uploads{}); !errors.Is(err, body.ErrUnsupportedMediaType) {
+			t.Fatalf("unexpected error: got %v want %v", err, body.ErrUnsupportedMediaType)
+		}
+
+	})
+}
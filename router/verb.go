@@ -21,6 +21,9 @@ const head = "head"
 // options is the HTTP OPTIONS method
 const options = "options"
 
+// wildcard is the method of routes serving every HTTP method, including the ones not listed in methods
+const wildcard = "*"
+
 var methods = []string{
 	get,
 	post,
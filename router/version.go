@@ -0,0 +1,110 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/opensaucerer/barf/constant"
+	"github.com/opensaucerer/barf/helper"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// vendor matches a vendor media type carrying an API version i.e application/vnd.zeina.v2+json
+var vendor = regexp.MustCompile(`^application/vnd\.(.+)\.v([0-9][\w.]*)(?:\+[\w.-]+)?$`)
+
+// Version creates a router on the default router whose routes are only served for the given API version.
+func Version(v string) *Router {
+	return Default.Version(v)
+}
+
+/*
+Version creates a router nested under r whose routes are only served for the given API version.
+The same path can be registered under several versions and the version is picked from the request headers.
+
+	barf.Version("1").Patch("/account/deposit", deposit)
+	barf.Version("2").Patch("/account/deposit", depositWithNarration)
+
+A version is requested with the API-Version header or a vendor media type such as Accept: application/vnd.zeina.v2+json.
+A leading v is ignored, so v2 and 2 are the same version.
+*/
+func (r *Router) Version(v string) *Router {
+	return &Router{
+		Entry:  "/",
+		Stack:  []typing.Middleware{},
+		parent: r,
+		api:    normalize(v),
+	}
+}
+
+// version returns the API version the router registers its routes for, if any
+func (r *Router) version() string {
+	for ; r != nil; r = r.parent {
+		if r.api != "" {
+			return r.api
+		}
+	}
+	return ""
+}
+
+// versioning returns the versioning config of the root router with its defaults applied
+func (r *Router) versioning() typing.Versioning {
+	v := typing.Versioning{}
+	if root := r.root(); root.Versioning != nil {
+		v = *root.Versioning
+	}
+	if v.Header == "" {
+		v.Header = constant.VersionHeader
+	}
+	v.Default = normalize(v.Default)
+	return v
+}
+
+// Negotiate returns the API version requested through the version header or the Accept header, or an empty string if none was requested.
+func (r *Router) Negotiate(req *http.Request) string {
+	v := r.versioning()
+	if requested := req.Header.Get(v.Header); requested != "" {
+		return normalize(requested)
+	}
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		media, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		if m := vendor.FindStringSubmatch(media); m != nil && (v.Vendor == "" || strings.EqualFold(m[1], v.Vendor)) {
+			return normalize(m[2])
+		}
+	}
+	return ""
+}
+
+// Announce sets the version header for the API version serving the request, along with the Deprecation and Sunset headers of deprecated versions.
+// Responses to requests asking for a version and responses of versioned routes vary by the version header and the Accept header,
+// such that shared caches do not serve the response of one version to clients asking for another.
+func (r *Router) Announce(w http.ResponseWriter, requested, version string) {
+	if requested == "" && version == "" {
+		return
+	}
+	v := r.versioning()
+	helper.Vary(w.Header(), v.Header, "Accept")
+	if version == "" {
+		return
+	}
+	w.Header().Set(v.Header, version)
+	for deprecated, sunset := range v.Deprecated {
+		if normalize(deprecated) != version {
+			continue
+		}
+		w.Header().Set("Deprecation", "true")
+		if !sunset.IsZero() {
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+	}
+}
+
+// normalize removes surrounding spaces and a leading v from the given version
+func normalize(v string) string {
+	v = strings.ToLower(strings.TrimSpace(v))
+	return strings.TrimPrefix(v, "v")
+}
@@ -0,0 +1,104 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// go test -v -run TestVersionUnit ./...
+func TestVersionUnit(t *testing.T) {
+
+	echo := func(source string) func(http.ResponseWriter, *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Source", source)
+		}
+	}
+
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	rt := New()
+	rt.Versioning = &typing.Versioning{
+		Default:    "v2",
+		Vendor:     "zeina",
+		Deprecated: map[string]time.Time{"1": sunset},
+	}
+	rt.Patch("/account/deposit", echo("unversioned"))
+	rt.Version("v1").Patch("/account/deposit", echo("v1"))
+	rt.Version("2").RetroFrame("/account").Patch("/deposit", echo("v2"))
+	rt.Get("/account/search", echo("unversioned"))
+	rt.Version("1").Patch("/account/withdraw", echo("v1"))
+
+	cases := []struct {
+		name    string
+		method  string
+		path    string
+		header  string
+		value   string
+		source  string
+		version string
+	}{
+		{"Should pick the version from the version header", http.MethodPatch, "/account/deposit", "API-Version", "1", "v1", "1"},
+		{"Should pick the version from a vendor media type", http.MethodPatch, "/account/deposit", "Accept", "application/json, application/vnd.zeina.v1+json", "v1", "1"},
+		{"Should ignore the media types of other vendors", http.MethodPatch, "/account/deposit", "Accept", "application/vnd.acme.v1+json", "v2", "2"},
+		{"Should serve the default version when none is requested", http.MethodPatch, "/account/deposit", "", "", "v2", "2"},
+		{"Should fall back to unversioned routes", http.MethodGet, "/account/search", "", "", "unversioned", ""},
+		{"Should fall back to unversioned routes for unknown versions", http.MethodPatch, "/account/deposit", "API-Version", "3", "unversioned", ""},
+		{"Should not serve unknown versions of versioned routes", http.MethodPatch, "/account/withdraw", "API-Version", "3", "", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			r := httptest.NewRequest(c.method, c.path, nil)
+			if c.header != "" {
+				r.Header.Set(c.header, c.value)
+			}
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, r)
+
+			if got := w.Header().Get("X-Source"); got != c.source {
+				t.Fatalf("unexpected route: got %v want %v", got, c.source)
+			}
+			if got := w.Header().Get("API-Version"); got != c.version {
+				t.Fatalf("unexpected version: got %v want %v", got, c.version)
+			}
+			vary := strings.Join(w.Header().Values("Vary"), ", ")
+			negotiated := c.header != "" || c.version != ""
+			if negotiated != (strings.Contains(vary, "API-Version") && strings.Contains(vary, "Accept")) {
+				t.Fatalf("unexpected Vary header: got %q want API-Version and Accept %v", vary, negotiated)
+			}
+
+		})
+	}
+
+	t.Run("Should announce the deprecation and sunset of old versions", func(t *testing.T) {
+
+		r := httptest.NewRequest(http.MethodPatch, "/account/deposit", nil)
+		r.Header.Set("API-Version", "v1")
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, r)
+
+		if w.Header().Get("Deprecation") != "true" {
+			t.Fatalf("unexpected Deprecation header: got %v want %v", w.Header().Get("Deprecation"), "true")
+		}
+		if w.Header().Get("Sunset") != sunset.Format(http.TimeFormat) {
+			t.Fatalf("unexpected Sunset header: got %v want %v", w.Header().Get("Sunset"), sunset.Format(http.TimeFormat))
+		}
+
+	})
+
+	t.Run("Should detect routes registered twice for the same version", func(t *testing.T) {
+
+		defer func() {
+			if c, ok := recover().(*Conflict); !ok || c.Kind != Duplicate {
+				t.Fatal("expected a duplicate conflict panic")
+			}
+		}()
+		rt.Version("1").Patch("/account/deposit", echo("v1"))
+
+	})
+}
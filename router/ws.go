@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/opensaucerer/barf/typing"
+	"github.com/opensaucerer/barf/ws"
+)
+
+// WS registers a WebSocket route on the default router. Handshakes are GET requests so they run behind the global middleware like any other route.
+func WS(path string, handler func(*ws.Conn), m ...typing.Middleware) *Route {
+	return Default.WS(path, handler, m...)
+}
+
+// WS registers a route on the router upgrading GET requests to WebSocket connections served by the given handler
+func (r *Router) WS(path string, handler func(*ws.Conn), m ...typing.Middleware) *Route {
+	return r.Get(path, ws.Handler(handler), m...)
+}
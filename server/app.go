@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/opensaucerer/barf/constant"
+	logger "github.com/opensaucerer/barf/log"
+	"github.com/opensaucerer/barf/middleware"
+	"github.com/opensaucerer/barf/router"
+	"github.com/opensaucerer/barf/router/ip"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// App is a barf application. It owns its router, middleware stack, config and http server
+// such that a single process can run several barf servers side by side.
+type App struct {
+	// Router is the root router of the app. Its Get, Post, RetroFrame... methods are promoted to the app.
+	*router.Router
+	// Augment is the config of the app
+	Augment *typing.Augment
+	// HTTP is the http server the app listens and serves with
+	HTTP *http.Server
+	// base is the handler the global middleware stack is injected around
+	base http.Handler
+	// stack is the global middleware stack injected through barf.Hippocampus(app).Hijack
+	stack []typing.Middleware
+	// beckoned is true once the app has started listening
+	beckoned bool
+	// signals receives the signals that shut the app down
+	signals chan os.Signal
+	// proxies holds the trusted proxies of the config, parsed once
+	proxies []*net.IPNet
+}
+
+// New creates a barf app with its own router and returns an error, if any.
+// You can optionally pass in a barf.Augment struct to override the default config.
+// To start the app, call app.Beck()
+func New(augmentation ...typing.Augment) (*App, error) {
+	return create(router.New(), make(chan os.Signal, 1), augmentation...)
+}
+
+// create prepares an app serving the routes of the given root router
+func create(rt *router.Router, signals chan os.Signal, augmentation ...typing.Augment) (*App, error) {
+	augu := typing.Augment{
+		MaxHeaderBytes:    constant.MaxHeaderBytes,
+		MaxBodySize:       constant.MaxBodySize,
+		ReadTimeout:       constant.ReadTimeout,
+		ReadHeaderTimeout: constant.ReadTimeout,
+		WriteTimeout:      constant.WriteTimeout,
+		ShutdownTimeout:   constant.ShutdownTimeout,
+		Port:              constant.Port,
+		Logging:           &constant.Logging,
+		LogFormat:         constant.LogFormat,
+		Recovery:          &constant.Recovery,
+		CORS:              &typing.CORS{},
+	}
+	if len(augmentation) > 0 {
+		// validate the struct
+		t := reflect.TypeOf(augmentation[0])
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("error: Stark() expects a struct, got %s", t.Kind())
+		}
+		// validate struct is a barf.Augment
+		if t.Name() != "Augment" {
+			return nil, fmt.Errorf("error: Stark() expects a barf.Augment struct, got %s", t.Name())
+		}
+		// override the default config
+		aug := augmentation[0]
+		// load default configurations
+		if aug.MaxHeaderBytes != 0 {
+			augu.MaxHeaderBytes = aug.MaxHeaderBytes
+		}
+		if aug.MaxBodySize != 0 {
+			augu.MaxBodySize = aug.MaxBodySize
+		}
+		if aug.ReadTimeout != 0 {
+			augu.ReadTimeout = aug.ReadTimeout
+		}
+		if aug.WriteTimeout != 0 {
+			augu.WriteTimeout = aug.WriteTimeout
+		}
+		if aug.ShutdownTimeout != 0 {
+			augu.ShutdownTimeout = aug.ShutdownTimeout
+		}
+		if aug.Port != "" {
+			augu.Port = fmt.Sprintf(":%s", aug.Port)
+		}
+		if aug.ReadHeaderTimeout != 0 {
+			augu.ReadHeaderTimeout = aug.ReadHeaderTimeout
+		}
+		if aug.Logging != nil {
+			augu.Logging = aug.Logging
+		}
+		if aug.LogFormat != "" {
+			augu.LogFormat = aug.LogFormat
+		}
+		augu.LogOutput = aug.LogOutput
+		if aug.Recovery != nil {
+			augu.Recovery = aug.Recovery
+		}
+		if aug.CORS != nil {
+			augu.CORS = aug.CORS
+		}
+		augu.StrictRouting = aug.StrictRouting
+		augu.Debug = aug.Debug
+		augu.Versioning = aug.Versioning
+		augu.TrustedProxies = aug.TrustedProxies
+		augu.CookieSecret = aug.CookieSecret
+		augu.WebSocket = aug.WebSocket
+		augu.Compression = aug.Compression
+		augu.ErrorHandler = aug.ErrorHandler
+		augu.ProblemDetails = aug.ProblemDetails
+	}
+
+	if augu.LogFormat != "text" && augu.LogFormat != "json" {
+		return nil, fmt.Errorf("error: unknown log format %s, expected text or json", augu.LogFormat)
+	}
+
+	// parse the trusted proxies once such that they can be relied on for every request
+	proxies, err := ip.Parse(augu.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("error: %w", err)
+	}
+
+	// routes registered from here on follow the app's routing strictness
+	rt.Strict = augu.StrictRouting
+	rt.Versioning = augu.Versioning
+
+	app := &App{
+		Router:  rt,
+		Augment: &augu,
+		stack:   []typing.Middleware{},
+		signals: signals,
+		proxies: proxies,
+	}
+
+	// the end of the chain. routes are dispatched by the router middleware
+	var r http.Handler = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	// wrap into router middleware
+	app.base = middleware.Router(JSON, app.Router)(r)
+
+	// create server
+	app.HTTP = &http.Server{
+		Addr:              app.Augment.Port,
+		ReadTimeout:       time.Duration(app.Augment.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(app.Augment.WriteTimeout) * time.Second,
+		MaxHeaderBytes:    app.Augment.MaxHeaderBytes,
+		ReadHeaderTimeout: time.Duration(app.Augment.ReadHeaderTimeout) * time.Second,
+	}
+
+	// serve the route table in debug mode
+	if app.Augment.Debug {
+		app.Get(constant.DebugRoutesPath, func(w http.ResponseWriter, r *http.Request) {
+			Response(w).Status(http.StatusOK).JSON(typing.Response{
+				Status:  true,
+				Message: "routes retrieved",
+				Data:    app.List(),
+			})
+		})
+	}
+
+	// this will load the CORS and Recovery middleware into the stack
+	Hippocampus(app).Hijack()
+	if *app.Augment.Recovery {
+		logger.Info("Recovery middleware added to base barf handler")
+	}
+
+	return app, nil
+}
+
+// ServeHTTP serves the request with the app's fully hijacked handler. This makes the app usable with httptest.
+func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.HTTP.Handler.ServeHTTP(w, r)
+}
+
+// Beck starts the app and returns an error, if any. It blocks until the app is shut down.
+func (a *App) Beck() error {
+	// return nil if app already Beckoned
+	if a.beckoned {
+		return nil
+	}
+	a.beckoned = true
+	// register shutdown function
+	go a.shutdown()
+	// print the route table in debug mode
+	if a.Augment.Debug {
+		var table strings.Builder
+		a.Print(&table)
+		logger.Debug("BARF routes:\n" + table.String())
+	}
+	// start server
+	logger.Info(fmt.Sprintf("BARF server started at http://localhost%s", a.Augment.Port))
+	if err := a.HTTP.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		a.beckoned = false
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully shuts down the app within its configured shutdown timeout.
+func (a *App) Shutdown() error {
+	// The context is used to inform the server it has ShutdownTimeout seconds to finish
+	// the request it is currently handling
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Augment.ShutdownTimeout)*time.Second)
+	defer cancel()
+	return a.HTTP.Shutdown(ctx)
+}
+
+// shutdown waits for a shutdown signal and gracefully shuts down the app.
+func (a *App) shutdown() {
+	// kill (no param) default send syscall.SIGTERM
+	// kill -2 is syscall.SIGINT
+	// kill -9 is syscall.SIGKILL but can't be catch, so don't need to add it
+	signal.Notify(a.signals, syscall.SIGINT, syscall.SIGTERM)
+	<-a.signals
+	logger.Warn("Shutting down BARF...")
+
+	if err := a.Shutdown(); err != nil {
+		logger.Error("BARF forced to shut down...")
+		log.Fatal()
+	}
+	logger.Debug("BARF exited!")
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// go test -v -run TestAppUnit ./...
+func TestAppUnit(t *testing.T) {
+
+	quiet := false
+
+	one, err := New(typing.Augment{Logging: &quiet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	two, err := New(typing.Augment{Logging: &quiet})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	one.Get("/v1/account/:number", func(w http.ResponseWriter, r *http.Request) {
+		Response(w).Status(http.StatusOK).JSON(typing.Response{Status: true})
+	})
+
+	t.Run("Should serve routes registered on the app", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		one.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/account/0123456789", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusOK)
+		}
+
+	})
+
+	t.Run("Should not share routes between apps", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		two.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/account/0123456789", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusNotFound)
+		}
+
+	})
+
+	t.Run("Should run middleware hijacked into the app", func(t *testing.T) {
+
+		Hippocampus(two).Hijack(func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Response(w).Status(http.StatusUnauthorized).JSON(nil)
+			})
+		})
+
+		w := httptest.NewRecorder()
+		two.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusUnauthorized)
+		}
+
+		w = httptest.NewRecorder()
+		one.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/account/0123456789", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("middleware leaked into another app: got %v want %v", w.Code, http.StatusOK)
+		}
+
+	})
+}
+
+// go test -v -run TestAppDefaultLoggingUnit ./...
+func TestAppDefaultLoggingUnit(t *testing.T) {
+
+	// logging is left at its default such that requests go through the logger
+	app, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		Response(w).Status(http.StatusOK).Send(typing.Response{Status: true, Message: "ok"})
+	})
+
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	t.Run("Should serve requests with logging enabled", func(t *testing.T) {
+		res, err := http.Get(server.URL + "/health")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status code: got %v want %v", res.StatusCode, http.StatusOK)
+		}
+		var data typing.Response
+		if err := json.Unmarshal(body, &data); err != nil || data.Message != "ok" {
+			t.Fatalf("unexpected body: got %s want a single response", body)
+		}
+	})
+}
+
+// go test -v -run TestAppLoggingUnit ./...
+func TestAppLoggingUnit(t *testing.T) {
+
+	var out bytes.Buffer
+	app, err := New(typing.Augment{LogFormat: "json", LogOutput: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Get("/v1/account/:number", func(w http.ResponseWriter, r *http.Request) {
+		Response(w).Status(http.StatusOK).JSON(typing.Response{Status: true, Message: Request(r).ID()})
+	})
+	app.Get("/v1/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("teller drawer jammed")
+	})
+
+	t.Run("Should log the route and request id of served requests", func(t *testing.T) {
+		out.Reset()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/account/0123456789", nil)
+		req.Header.Set("X-Request-ID", "teller-42")
+		app.ServeHTTP(w, req)
+
+		if !strings.Contains(w.Body.String(), "teller-42") {
+			t.Fatalf("unexpected body: got %v want the request id", w.Body.String())
+		}
+		line := out.String()
+		if !strings.Contains(line, `"request_id":"teller-42"`) || !strings.Contains(line, `"route":"/v1/account/:number"`) || !strings.Contains(line, `"status":200`) {
+			t.Fatalf("unexpected log: got %v", line)
+		}
+	})
+
+	t.Run("Should log recovered panics as 500", func(t *testing.T) {
+		out.Reset()
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/panic", nil))
+		if !strings.Contains(out.String(), `"status":500`) {
+			t.Fatalf("unexpected log: got %v", out.String())
+		}
+	})
+
+	t.Run("Should reject unknown log formats", func(t *testing.T) {
+		if _, err := New(typing.Augment{LogFormat: "xml"}); err == nil {
+			t.Fatalf("unexpected error: got %v want an error", err)
+		}
+	})
+}
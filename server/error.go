@@ -0,0 +1,137 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	logger "github.com/opensaucerer/barf/log"
+	"github.com/opensaucerer/barf/router/body"
+	"github.com/opensaucerer/barf/router/cookie"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// problem is the body of an application/problem+json response as defined by RFC 7807
+type problem struct {
+	Type     string             `json:"type"`
+	Title    string             `json:"title"`
+	Status   int                `json:"status"`
+	Detail   string             `json:"detail,omitempty"`
+	Instance string             `json:"instance,omitempty"`
+	Code     string             `json:"code,omitempty"`
+	Errors   typing.FieldErrors `json:"errors,omitempty"`
+}
+
+/*
+Error answers the request with the given error. Errors are answered, in order:
+
+	as the barf.Error they are or wrap
+	as the errors of barf itself i.e 413, 415 and 422 for the errors of barf.Request(r).Body().Format
+	with the status set with Status, if any
+	as the barf.Error returned by barf.Augment.ErrorHandler
+	as a 500 otherwise, without exposing the error
+
+The body is a barf.Res envelope unless barf.Augment.ProblemDetails is set or the request accepts application/problem+json.
+Causes are logged for 5xx errors but never sent.
+
+	if err := barf.Request(r).Body().Format(&data); err != nil {
+		barf.Response(w).Status(http.StatusBadRequest).Error(err)
+		return
+	}
+*/
+func (r *response) Error(err error) {
+	if err == nil {
+		return
+	}
+	req := origin(r.writer)
+	aug := &typing.Augment{}
+	switch {
+	case req != nil:
+		aug = Request(req).augment()
+	case Default != nil:
+		aug = Default.Augment
+	}
+
+	e := resolve(err, r.code, aug.ErrorHandler)
+	if e.Status >= http.StatusInternalServerError {
+		logger.Error(err.Error())
+	}
+	r.body = e
+
+	if !aug.ProblemDetails && (req == nil || !strings.Contains(req.Header.Get("Accept"), "application/problem+json")) {
+		r.writer.Header().Set("Content-Type", "application/json")
+		r.writer.WriteHeader(e.Status)
+		res := typing.Response{Status: false, Message: e.Error()}
+		if len(e.Fields) > 0 {
+			res.Data = e.Fields
+		}
+		json.NewEncoder(r.writer).Encode(res)
+		return
+	}
+
+	p := problem{
+		Type:   e.Type,
+		Title:  http.StatusText(e.Status),
+		Status: e.Status,
+		Code:   e.Code,
+		Errors: e.Fields,
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if e.Message != "" {
+		p.Detail = e.Message
+	}
+	if req != nil {
+		p.Instance = req.URL.Path
+	}
+	r.writer.Header().Set("Content-Type", "application/problem+json")
+	r.writer.WriteHeader(e.Status)
+	json.NewEncoder(r.writer).Encode(p)
+}
+
+// resolve returns the barf error the given error is answered with
+func resolve(err error, status int, handler func(error) *typing.Error) *typing.Error {
+	var e *typing.Error
+	if errors.As(err, &e) {
+		if e.Status == 0 {
+			fixed := *e
+			fixed.Status = http.StatusInternalServerError
+			return &fixed
+		}
+		return e
+	}
+
+	var fields typing.FieldErrors
+	switch {
+	case errors.Is(err, body.ErrUnsupportedMediaType):
+		return &typing.Error{Status: http.StatusUnsupportedMediaType, Code: "unsupported_media_type", Message: err.Error(), Cause: err}
+	case errors.Is(err, body.ErrRequestEntityTooLarge):
+		return &typing.Error{Status: http.StatusRequestEntityTooLarge, Code: "request_entity_too_large", Message: err.Error(), Cause: err}
+	case errors.Is(err, cookie.ErrInvalidSignature):
+		return &typing.Error{Status: http.StatusBadRequest, Code: "invalid_signature", Message: err.Error(), Cause: err}
+	case errors.As(err, &fields):
+		return &typing.Error{Status: http.StatusUnprocessableEntity, Code: "validation_failed", Message: err.Error(), Fields: fields, Cause: err}
+	}
+
+	if status != 0 {
+		return &typing.Error{Status: status, Message: err.Error(), Cause: err}
+	}
+
+	if handler != nil {
+		if e := handler(err); e != nil {
+			// the handler may return shared errors so they are not modified
+			mapped := *e
+			if mapped.Status == 0 {
+				mapped.Status = http.StatusInternalServerError
+			}
+			if mapped.Cause == nil {
+				mapped.Cause = err
+			}
+			return &mapped
+		}
+	}
+
+	return &typing.Error{Status: http.StatusInternalServerError, Code: "internal_error", Cause: err}
+}
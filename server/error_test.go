@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opensaucerer/barf/router/body"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// go test -v -run TestResponseErrorUnit ./...
+func TestResponseErrorUnit(t *testing.T) {
+
+	errAccountNotFound := errors.New("account not found")
+
+	quiet := false
+	app, err := New(typing.Augment{Logging: &quiet, ErrorHandler: func(err error) *typing.Error {
+		if errors.Is(err, errAccountNotFound) {
+			return &typing.Error{Status: http.StatusNotFound, Code: "account_not_found", Message: err.Error()}
+		}
+		return nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := map[string]error{
+		"typed":    fmt.Errorf("deposit: %w", &typing.Error{Status: http.StatusUnprocessableEntity, Code: "insufficient_funds", Message: "insufficient funds"}),
+		"mapped":   fmt.Errorf("search: %w", errAccountNotFound),
+		"media":    fmt.Errorf("%w: text/plain", body.ErrUnsupportedMediaType),
+		"fields":   typing.FieldErrors{{Field: "email", Message: "email is required"}},
+		"decode":   errors.New("unexpected end of JSON input"),
+		"internal": errors.New("pq: connection refused"),
+	}
+	app.Patch("/v1/account/:case", func(w http.ResponseWriter, r *http.Request) {
+		params, _ := Request(r).Params().JSON()
+		res := Response(w)
+		if params["case"] == "decode" {
+			res.Status(http.StatusBadRequest)
+		}
+		res.Error(errs[params["case"]])
+	})
+
+	cases := []struct {
+		name    string
+		path    string
+		status  int
+		code    string
+		message string
+	}{
+		{"Should answer barf errors with their status", "typed", http.StatusUnprocessableEntity, "insufficient_funds", "insufficient funds"},
+		{"Should map errors with the error handler", "mapped", http.StatusNotFound, "account_not_found", "search: account not found"},
+		{"Should map the errors of barf", "media", http.StatusUnsupportedMediaType, "unsupported_media_type", "unsupported media type: text/plain"},
+		{"Should map field errors with their fields", "fields", http.StatusUnprocessableEntity, "validation_failed", "email is required"},
+		{"Should answer other errors with the status of the response", "decode", http.StatusBadRequest, "", "unexpected end of JSON input"},
+		{"Should hide unmapped errors behind a 500", "internal", http.StatusInternalServerError, "internal_error", "Internal Server Error"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/v1/account/"+c.path, nil))
+
+			var res typing.Response
+			json.NewDecoder(w.Body).Decode(&res)
+			if w.Code != c.status || w.Header().Get("Content-Type") != "application/json" {
+				t.Fatalf("unexpected response: got %v %v want %v", w.Code, w.Header().Get("Content-Type"), c.status)
+			}
+			if res.Status || res.Message != c.message {
+				t.Fatalf("unexpected message: got %v want %v", res.Message, c.message)
+			}
+
+			w = httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, "/v1/account/"+c.path, nil)
+			r.Header.Set("Accept", "application/problem+json, application/json")
+			app.ServeHTTP(w, r)
+
+			var p problem
+			json.NewDecoder(w.Body).Decode(&p)
+			if w.Code != c.status || w.Header().Get("Content-Type") != "application/problem+json" {
+				t.Fatalf("unexpected response: got %v %v want %v", w.Code, w.Header().Get("Content-Type"), c.status)
+			}
+			if p.Status != c.status || p.Code != c.code || p.Title != http.StatusText(c.status) || p.Type != "about:blank" || p.Instance != "/v1/account/"+c.path {
+				t.Fatalf("unexpected problem: got %+v", p)
+			}
+			if c.path == "fields" && (len(p.Errors) != 1 || p.Errors[0].Field != "email") {
+				t.Fatalf("unexpected fields: got %v", p.Errors)
+			}
+			if strings.Contains(p.Detail, "connection refused") {
+				t.Fatalf("unexpected detail: got %v", p.Detail)
+			}
+
+		})
+	}
+}
@@ -7,57 +7,79 @@ import (
 )
 
 type hippocampus struct {
+	app    *App
 	router router.Hippocampus
 	stack  []typing.Middleware
 }
 
 /*
-Hippocampus prepares the given barf router or base barf handler for hijacking. To take over the base barf handler, omit the router argument.
+Hippocampus prepares the given barf router, barf app or base barf handler for hijacking. To take over the base barf handler, omit the router argument.
 
-Note: the base barf handler is the one that is created by the barf.Stark() function and can only be hijacked before the barf.Beck() function is called.
+Note: the base barf handler is the one that is created by the barf.Stark() function and can only be hijacked before the barf.Beck() function is called. The same applies to the handler of an app created by barf.New() and its app.Beck() method.
 */
 func Hippocampus(r ...router.Hippocampus) *hippocampus {
 	h := &hippocampus{}
 	if len(r) > 0 {
-		h.router = r[0].(*router.Router)
-		h.stack = r[0].(*router.Router).Stack
+		switch rt := r[0].(type) {
+		case *App:
+			h.app = rt
+			h.stack = rt.stack
+		default:
+			h.router = rt.(*router.Router)
+			h.stack = rt.(*router.Router).Stack
+		}
 	} else {
-		if Barf.Router != nil {
-			h.stack = Barf.Stack
+		h.app = Default
+		if Default != nil {
+			h.stack = Default.stack
 		}
 	}
 	return h
 }
 
-// Hijack takes over the given barf router or base barf handler by injecting the given middleware.
+// Hijack takes over the given barf router, barf app or base barf handler by injecting the given middleware.
 func (h *hippocampus) Hijack(m ...typing.Middleware) {
 	if len(m) > 0 {
-		if h.stack != nil {
-			h.stack = append(h.stack, m...)
-		}
+		h.stack = append(h.stack, m...)
 	}
 	// hijack base barf handler
 	if h.router == nil {
 
-		Barf.Stack = h.stack
+		app := h.app
+		app.stack = h.stack
 
 		// hijacking the base barf handler is only possible before the barf.Beck() function is called
-		if Beckoned == nil || !*Beckoned {
-			// create a copy of Barf.Router
-			r := Barf.Router
+		if !app.beckoned {
+			// create a copy of the base handler
+			r := app.base
 			for i := range h.stack {
 				r = h.stack[len(h.stack)-1-i](r)
 			}
-			// add cors middleware such that it is called first before any user-defined middleware
-			r = middleware.CORS(middleware.Prepare(*Augment.CORS))(r)
+			// limit and cache the request body such that user-defined middleware and handlers can all read it
+			r = middleware.Body(app.Augment.MaxBodySize, JSON)(r)
+			// make the app config available to barf.Request(r)
+			r = middleware.Augment(app.Augment, app.proxies)(r)
+			// remove uploaded files once the request is served
+			r = middleware.Cleanup(r)
 			// add recovery middleware
-			if Augment.Recovery != nil && *Augment.Recovery {
+			if app.Augment.Recovery != nil && *app.Augment.Recovery {
 				r = middleware.Recover(JSON)(r)
 			}
-			HTTP.Handler = r
+			// compress responses around the recovery such that the response of a panic is part of the compressed body
+			if app.Augment.Compression != nil {
+				r = middleware.Compress(*app.Augment.Compression)(r)
+			}
+			// add cors middleware such that it is called first before any user-defined middleware
+			r = middleware.CORS(middleware.Prepare(*app.Augment.CORS))(r)
+			// log requests once they are served, including the ones that panicked
+			if *app.Augment.Logging {
+				r = middleware.Logger(app.Augment, app.proxies)(r)
+			}
+			app.HTTP.Handler = r
 		}
 	} else {
+		// router stacks are applied when their routes are dispatched so middleware
+		// injected after the routes were registered still runs
 		h.router.(*router.Router).Stack = h.stack
-		// TODO: implement hijacking of router
 	}
 }
@@ -1,26 +1,24 @@
 package server
 
 import (
-	"net/http"
-
+	"github.com/opensaucerer/barf/constant"
 	"github.com/opensaucerer/barf/router"
 	"github.com/opensaucerer/barf/typing"
 )
 
-var (
-	HTTP *http.Server
-
-	Mux *http.ServeMux
-
-	Augment *typing.Augment
+// Default is the app created by barf.Stark(). It serves the routes registered with the package level functions such as barf.Get
+var Default *App
 
-	Beckoned *bool
-
-	Barf *(struct {
-		Router router.Hippocampus
-		Stack  []typing.Middleware
-	}) = &struct {
-		Router router.Hippocampus
-		Stack  []typing.Middleware
-	}{}
-)
+// Stark creates the default app, if it does not exist yet, and returns an error, if any.
+func Stark(augmentation ...typing.Augment) error {
+	// return nil if the default app already exists
+	if Default != nil {
+		return nil
+	}
+	app, err := create(router.Default, constant.ShutdownChan, augmentation...)
+	if err != nil {
+		return err
+	}
+	Default = app
+	return nil
+}
@@ -1,11 +1,19 @@
 package server
 
 import (
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/opensaucerer/barf/etag"
 	"github.com/opensaucerer/barf/router/body"
+	"github.com/opensaucerer/barf/router/cookie"
+	"github.com/opensaucerer/barf/router/header"
+	"github.com/opensaucerer/barf/router/ip"
 	"github.com/opensaucerer/barf/router/param"
 	"github.com/opensaucerer/barf/router/query"
+	"github.com/opensaucerer/barf/router/upload"
+	"github.com/opensaucerer/barf/typing"
 )
 
 type request struct {
@@ -33,3 +41,86 @@ func (r *request) Params() param.P {
 func (r *request) Query() query.Q {
 	return query.Query(r.request)
 }
+
+// Header prepares the barf request with the request headers for typed access
+func (r *request) Header() header.H {
+	return header.Header(r.request)
+}
+
+// Cookies prepares the barf request with the request cookies. Signed cookies are verified with barf.Augment.CookieSecret.
+func (r *request) Cookies() cookie.C {
+	return cookie.Cookies(r.request, r.augment().CookieSecret)
+}
+
+// Bearer returns the token of an Authorization header using the Bearer scheme and whether there was one
+func (r *request) Bearer() (string, bool) {
+	return r.Header().Bearer()
+}
+
+// IP returns the IP address of the client. The Forwarded and X-Forwarded-For headers are only honoured for requests
+// coming from the proxies listed in barf.Augment.TrustedProxies.
+func (r *request) IP() string {
+	return ip.Client(r.request, r.proxies())
+}
+
+// ID returns the ID the request is logged with, as sent by the client in the X-Request-ID header or generated by barf.
+// Requests are only given an ID when logging is enabled.
+func (r *request) ID() string {
+	id, _ := r.request.Context().Value(typing.RequestIDCtxKey{}).(string)
+	return id
+}
+
+/*
+Files streams the files of a multipart/form-data request into the temporary directory, or the storage of the given barf.Uploads config,
+and returns them along with the values of the form. Files are removed once the request is served unless file.Keep() is called.
+
+	form, err := barf.Request(r).Files(barf.Uploads{MaxFileSize: 5 << 20, Allowed: []string{"image/*", "application/pdf"}})
+	passport := form.File("passport")
+
+Uploads are limited by barf.Augment.MaxBodySize too. The request body should not be read with Body() before or after calling Files.
+*/
+func (r *request) Files(opts ...typing.Uploads) (*upload.Form, error) {
+	var o typing.Uploads
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return upload.Files(r.request, o)
+}
+
+/*
+Preconditions evaluates the If-Match, If-Unmodified-Since, If-None-Match and If-Modified-Since headers of the request
+against the current entity tag and modification time of the resource. It returns 0 when the request should proceed,
+http.StatusNotModified or http.StatusPreconditionFailed otherwise. Mutating handlers use it for optimistic concurrency.
+
+	if barf.Request(r).Preconditions(version(account), account.UpdatedAt) != 0 {
+		barf.Response(w).Status(http.StatusPreconditionFailed).JSON(barf.Res{Message: "account was modified"})
+		return
+	}
+*/
+func (r *request) Preconditions(tag string, modified time.Time) int {
+	return etag.Check(r.request, tag, modified)
+}
+
+// proxies returns the trusted proxies of the app serving the request, or those of the app created by barf.Stark() for handlers
+// called outside of a barf app
+func (r *request) proxies() []*net.IPNet {
+	if proxies, ok := r.request.Context().Value(typing.ProxiesCtxKey{}).([]*net.IPNet); ok {
+		return proxies
+	}
+	if Default != nil {
+		return Default.proxies
+	}
+	return nil
+}
+
+// augment returns the config of the app serving the request. Handlers called outside of a barf app, i.e in tests,
+// get the config of the app created by barf.Stark(), if any, or an empty config.
+func (r *request) augment() *typing.Augment {
+	if aug, ok := r.request.Context().Value(typing.AugmentCtxKey{}).(*typing.Augment); ok {
+		return aug
+	}
+	if Default != nil {
+		return Default.Augment
+	}
+	return &typing.Augment{}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/opensaucerer/barf/router/cookie"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// go test -v -run TestRequestBodyUnit ./...
+func TestRequestBodyUnit(t *testing.T) {
+
+	quiet := false
+	app, err := New(typing.Augment{Logging: &quiet, MaxBodySize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a signature check reading the body before the handler
+	Hippocampus(app).Hijack(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if raw, _ := Request(r).Body().Bytes(); len(raw) > 0 {
+				w.Header().Set("X-Signed", "true")
+			}
+			h.ServeHTTP(w, r)
+		})
+	})
+	app.Patch("/v1/account/deposit", func(w http.ResponseWriter, r *http.Request) {
+		var data struct {
+			Number string `json:"account_number"`
+		}
+		if err := Request(r).Body().Format(&data); err != nil {
+			Response(w).Status(http.StatusRequestEntityTooLarge).JSON(typing.Response{Message: err.Error()})
+			return
+		}
+		Response(w).Status(http.StatusOK).JSON(typing.Response{Status: true, Message: data.Number})
+	})
+
+	t.Run("Should let middleware and handlers read the same body", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/v1/account/deposit", strings.NewReader(`{"account_number":"0123456789"}`)))
+
+		if w.Code != http.StatusOK || w.Header().Get("X-Signed") != "true" {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), "0123456789") {
+			t.Fatalf("unexpected body: got %v", w.Body.String())
+		}
+
+	})
+
+	t.Run("Should answer 413 for bodies declaring a size over the limit", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/v1/account/deposit", strings.NewReader(strings.Repeat("a", 65))))
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusRequestEntityTooLarge)
+		}
+
+	})
+
+	t.Run("Should fail chunked bodies growing over the limit", func(t *testing.T) {
+
+		r := httptest.NewRequest(http.MethodPatch, "/v1/account/deposit", strings.NewReader(`{"account_number":"`+strings.Repeat("0", 64)+`"}`))
+		r.ContentLength = -1
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "too large") {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Body.String())
+		}
+
+	})
+}
+
+// go test -v -run TestRequestAccessorUnit ./...
+func TestRequestAccessorUnit(t *testing.T) {
+
+	quiet := false
+	app, err := New(typing.Augment{Logging: &quiet, TrustedProxies: []string{"10.0.0.0/8"}, CookieSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Get("/v1/account/search", func(w http.ResponseWriter, r *http.Request) {
+		req := Request(r)
+		token, _ := req.Bearer()
+		session, _ := req.Cookies().Signed("session")
+		limit, _ := req.Header().Int("X-Limit")
+		Response(w).Status(http.StatusOK).JSON(map[string]interface{}{
+			"token":   token,
+			"session": session,
+			"limit":   limit,
+			"ip":      req.IP(),
+		})
+	})
+
+	t.Run("Should expose the headers, cookies, token and client IP", func(t *testing.T) {
+
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/search", nil)
+		r.RemoteAddr = "10.0.0.2:443"
+		r.Header.Set("X-Forwarded-For", "198.51.100.1")
+		r.Header.Set("Authorization", "Bearer abc.def")
+		r.Header.Set("X-Limit", "20")
+		r.AddCookie(cookie.Cookies(r, "secret").Sign(&http.Cookie{Name: "session", Value: "teller"}))
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		want := `{"ip":"198.51.100.1","limit":20,"session":"teller","token":"abc.def"}`
+		if strings.TrimSpace(w.Body.String()) != want {
+			t.Fatalf("unexpected body: got %v want %v", w.Body.String(), want)
+		}
+
+	})
+
+	t.Run("Should reject invalid trusted proxies", func(t *testing.T) {
+
+		if _, err := New(typing.Augment{Logging: &quiet, TrustedProxies: []string{"proxy"}}); err == nil {
+			t.Fatal("expected error but got none")
+		}
+
+	})
+}
+
+// go test -v -run TestRequestFilesUnit ./...
+func TestRequestFilesUnit(t *testing.T) {
+
+	quiet := false
+	app, err := New(typing.Augment{Logging: &quiet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	var stored string
+	app.Post("/v1/account/kyc", func(w http.ResponseWriter, r *http.Request) {
+		form, err := Request(r).Files(typing.Uploads{Dir: dir, Allowed: []string{"application/pdf"}})
+		if err != nil {
+			Response(w).Status(http.StatusUnsupportedMediaType).JSON(typing.Response{Message: err.Error()})
+			return
+		}
+		stored = form.File("document").Key
+		if _, err := os.Stat(stored); err != nil {
+			t.Errorf("file was not stored: %v", err)
+		}
+		Response(w).Status(http.StatusCreated).JSON(typing.Response{Status: true})
+	})
+
+	t.Run("Should remove uploaded files once the request is served", func(t *testing.T) {
+
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		fw, _ := mw.CreateFormFile("document", "statement.pdf")
+		fw.Write([]byte("%PDF-1.7\n"))
+		mw.Close()
+		r := httptest.NewRequest(http.MethodPost, "/v1/account/kyc", &buf)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Body.String())
+		}
+		if _, err := os.Stat(stored); !os.IsNotExist(err) {
+			t.Fatalf("file was not removed: %v", err)
+		}
+
+	})
+}
@@ -1,9 +1,17 @@
 package server
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/opensaucerer/barf/encode"
+	"github.com/opensaucerer/barf/etag"
+	"github.com/opensaucerer/barf/helper"
+	"github.com/opensaucerer/barf/sse"
 	"github.com/opensaucerer/barf/typing"
 )
 
@@ -22,14 +30,160 @@ type response struct {
 	code   int
 	body   interface{}
 	writer http.ResponseWriter
+	// etag is the entity tag set with ETag, if any
+	etag string
+	// modified is the modification time set with LastModified, if any
+	modified time.Time
 }
 
 // JSON writes a JSON response to the response writer
 func (r *response) JSON(data interface{}) {
 	r.body = data
-	r.writer.Header().Set("Content-Type", "application/json")
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(data); err != nil {
+		JSON(r.writer, false, http.StatusInternalServerError, "Internal Server Error: "+err.Error(), nil)
+		return
+	}
+	r.write("application/json", buf.Bytes())
+}
+
+/*
+Send writes the given data in the format the request accepts best among the registered encoders.
+JSON, XML, CSV and MessagePack are built in and more can be registered with barf.Encoder.
+
+	barf.Response(w).Status(http.StatusOK).Send(transactions)
+
+Requests accepting none of the registered media types are answered with a 406. Requests without an Accept header get JSON.
+*/
+func (r *response) Send(data interface{}) {
+	r.body = data
+	accept := ""
+	if req := origin(r.writer); req != nil {
+		accept = req.Header.Get("Accept")
+	}
+	helper.Vary(r.writer.Header(), "Accept")
+	media, encoder, ok := encode.Negotiate(accept)
+	if !ok {
+		JSON(r.writer, false, http.StatusNotAcceptable, fmt.Sprintf("None of the media types accepted by the request are available: %s", accept), nil)
+		return
+	}
+	var buf bytes.Buffer
+	if err := encoder(&buf, data); err != nil {
+		JSON(r.writer, false, http.StatusInternalServerError, "Internal Server Error: "+err.Error(), nil)
+		return
+	}
+	if strings.HasPrefix(media, "text/") {
+		media += "; charset=utf-8"
+	}
+	r.write(media, buf.Bytes())
+}
+
+/*
+ETag sets the entity tag of the response, such as one derived from the version of a resource. Weak tags start with W/.
+
+	barf.Response(w).Status(http.StatusOK).ETag(barf.StrongETag([]byte(account.UpdatedAt.String()))).JSON(account)
+
+Without it, 200 responses to GET and HEAD requests are tagged with a strong tag computed from their body.
+*/
+func (r *response) ETag(tag string) *response {
+	r.etag = tag
+	return r
+}
+
+// LastModified sets the time the resource was last modified at, which If-Modified-Since and If-Unmodified-Since are checked against
+func (r *response) LastModified(t time.Time) *response {
+	r.modified = t
+	return r
+}
+
+// write writes the given encoded body. 200 responses to GET and HEAD requests are answered with a 304 when the client
+// has the current representation already, and with a 412 when a precondition of the request failed.
+func (r *response) write(contentType string, body []byte) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	h := r.writer.Header()
+	req := origin(r.writer)
+	conditional := r.code == http.StatusOK && req != nil && (req.Method == http.MethodGet || req.Method == http.MethodHead)
+
+	tag := r.etag
+	if tag == "" && conditional {
+		tag = etag.Strong(body)
+	}
+	if tag != "" {
+		h.Set("ETag", tag)
+	}
+	if !r.modified.IsZero() {
+		h.Set("Last-Modified", r.modified.UTC().Format(http.TimeFormat))
+	}
+
+	if conditional {
+		switch etag.Check(req, tag, r.modified) {
+		case http.StatusNotModified:
+			r.writer.WriteHeader(http.StatusNotModified)
+			return
+		case http.StatusPreconditionFailed:
+			JSON(r.writer, false, http.StatusPreconditionFailed, "The preconditions of the request failed for the current representation of the resource", nil)
+			return
+		}
+	}
+
+	h.Set("Content-Type", contentType)
 	r.writer.WriteHeader(r.code)
-	json.NewEncoder(r.writer).Encode(data)
+	r.writer.Write(body)
+}
+
+/*
+Stream opens a server-sent events stream for the request. Events are flushed as they are sent and the stream is closed
+once the client goes away or the request is served. Clients reconnecting with a Last-Event-ID header can be caught up
+with the events they missed.
+
+	stream, err := barf.Response(w).Stream()
+	if err != nil {
+		return
+	}
+	for {
+		select {
+		case <-stream.Done():
+			return
+		case tx := <-feed:
+			stream.Send(barf.Event{ID: tx.SessionId, Event: "transaction", Data: tx})
+		}
+	}
+
+A heartbeat comment is sent every 15 seconds unless configured otherwise with barf.SSE.
+*/
+func (r *response) Stream(opts ...typing.SSE) (*sse.Stream, error) {
+	var o typing.SSE
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	req := origin(r.writer)
+	s, err := sse.Open(r.writer, req, o)
+	if err != nil {
+		return nil, err
+	}
+	// the stream must not write to the response writer once the request is served
+	if req != nil {
+		if cleanup, ok := req.Context().Value(typing.CleanupCtxKey{}).(*[]func()); ok {
+			*cleanup = append(*cleanup, s.Close)
+		}
+	}
+	return s, nil
+}
+
+// origin returns the request the given response writer was wrapped with by barf, if any
+func origin(w http.ResponseWriter) *http.Request {
+	for {
+		if rw, ok := w.(interface{ Request() *http.Request }); ok {
+			return rw.Request()
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		w = u.Unwrap()
+	}
 }
 
 // Status loads a barf response with the given status code
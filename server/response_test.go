@@ -0,0 +1,325 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opensaucerer/barf/sse"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// go test -v -run TestResponseSendUnit ./...
+func TestResponseSendUnit(t *testing.T) {
+
+	quiet := false
+	app, err := New(typing.Augment{Logging: &quiet})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type transaction struct {
+		Number string  `json:"number" xml:"number"`
+		Amount float64 `json:"amount" xml:"amount"`
+	}
+	app.Get("/v1/account/transactions", func(w http.ResponseWriter, r *http.Request) {
+		Response(w).Status(http.StatusOK).Send(typing.Response{
+			Status:  true,
+			Data:    []transaction{{Number: "0123456789", Amount: 2500}},
+			Message: "transactions retrieved",
+		})
+	})
+	app.Version("2").Get("/v1/account/statement", func(w http.ResponseWriter, r *http.Request) {
+		Response(w).Status(http.StatusOK).Send(typing.Response{Status: true, Message: "statement retrieved"})
+	})
+
+	cases := []struct {
+		name        string
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"Should default to JSON", "", http.StatusOK, "application/json", `"number":"0123456789"`},
+		{"Should encode CSV", "text/csv", http.StatusOK, "text/csv; charset=utf-8", "number,amount\n0123456789,2500\n"},
+		{"Should encode XML", "application/xml", http.StatusOK, "application/xml", "<number>0123456789</number>"},
+		{"Should encode MessagePack", "application/msgpack", http.StatusOK, "application/msgpack", "0123456789"},
+		{"Should encode JSON for vendor types requesting a version", "application/vnd.zeina.v2+json", http.StatusOK, "application/json", `"number":"0123456789"`},
+		{"Should answer 406 for unavailable media types", "application/pdf", http.StatusNotAcceptable, "application/json", "application/pdf"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/v1/account/transactions", nil)
+			if c.accept != "" {
+				r.Header.Set("Accept", c.accept)
+			}
+			app.ServeHTTP(w, r)
+
+			if w.Code != c.code {
+				t.Fatalf("unexpected status: got %v want %v", w.Code, c.code)
+			}
+			if w.Header().Get("Content-Type") != c.contentType {
+				t.Fatalf("unexpected content type: got %v want %v", w.Header().Get("Content-Type"), c.contentType)
+			}
+			if vary := strings.Join(w.Header().Values("Vary"), ", "); !strings.Contains(vary, "Accept") {
+				t.Fatalf("unexpected vary: got %v want %v", vary, "Accept")
+			}
+			if !strings.Contains(w.Body.String(), c.body) {
+				t.Fatalf("unexpected body: got %q want %q", w.Body.String(), c.body)
+			}
+
+		})
+	}
+
+	t.Run("Should send versioned routes to clients requesting the version with a vendor type", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/statement", nil)
+		r.Header.Set("Accept", "application/vnd.zeina.v2+json")
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK || w.Header().Get("API-Version") != "2" || !strings.Contains(w.Body.String(), "statement retrieved") {
+			t.Fatalf("unexpected response: got %v %v %s", w.Code, w.Header().Get("API-Version"), w.Body.String())
+		}
+
+	})
+}
+
+// go test -v -run TestResponseStreamUnit ./...
+func TestResponseStreamUnit(t *testing.T) {
+
+	quiet := false
+	app, err := New(typing.Augment{Logging: &quiet})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stream *sse.Stream
+	app.Get("/v1/account/transactions/feed", func(w http.ResponseWriter, r *http.Request) {
+		stream, err = Response(w).Stream()
+		if err != nil {
+			return
+		}
+		stream.Send(sse.Event{ID: "A1", Event: "transaction", Data: "0123456789"})
+	})
+
+	t.Run("Should stream events and close the stream once served", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/account/transactions/feed", nil))
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if w.Header().Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("unexpected content type: got %v", w.Header().Get("Content-Type"))
+		}
+		if w.Body.String() != "id: A1\nevent: transaction\ndata: 0123456789\n\n" {
+			t.Fatalf("unexpected body: got %q", w.Body.String())
+		}
+		if err := stream.Send(sse.Event{Data: "late"}); err != sse.ErrClosed {
+			t.Fatalf("unexpected error: got %v want %v", err, sse.ErrClosed)
+		}
+
+	})
+}
+
+// go test -v -run TestResponseCompressionUnit ./...
+func TestResponseCompressionUnit(t *testing.T) {
+
+	quiet, recovery := false, true
+	app, err := New(typing.Augment{Logging: &quiet, Recovery: &recovery, Compression: &typing.Compression{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Get("/v1/account/statement", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, strings.Repeat("statement ", 200))
+		panic("statement printer jammed")
+	})
+	app.Get("/v1/account/transactions/feed", func(w http.ResponseWriter, r *http.Request) {
+		stream, err := Response(w).Stream()
+		if err != nil {
+			return
+		}
+		stream.Send(sse.Event{ID: "A1", Data: "0123456789"})
+	})
+	app.Get("/v1/account/transactions", func(w http.ResponseWriter, r *http.Request) {
+		Response(w).Status(http.StatusOK).JSON(typing.Response{Status: true, Message: strings.Repeat("transaction ", 128)})
+	})
+
+	t.Run("Should stream compressed events", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/transactions/feed", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		app.ServeHTTP(w, r)
+
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("unexpected encoding: got %v want %v", w.Header().Get("Content-Encoding"), "gzip")
+		}
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(zr)
+		if string(body) != "id: A1\ndata: 0123456789\n\n" {
+			t.Fatalf("unexpected body: got %q", body)
+		}
+
+	})
+
+	t.Run("Should answer 304 with the weak tag of the compressed response", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/transactions", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		app.ServeHTTP(w, r)
+
+		tag := w.Header().Get("ETag")
+		if w.Header().Get("Content-Encoding") != "gzip" || !strings.HasPrefix(tag, "W/") {
+			t.Fatalf("unexpected response: got %v %v want a weak tag for gzip", w.Header().Get("Content-Encoding"), tag)
+		}
+
+		w = httptest.NewRecorder()
+		r.Header.Set("If-None-Match", tag)
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotModified || w.Header().Get("ETag") != tag {
+			t.Fatalf("unexpected revalidation: got %v %v want %v %v", w.Code, w.Header().Get("ETag"), http.StatusNotModified, tag)
+		}
+
+	})
+
+	t.Run("Should send the same header for HEAD requests as for GET requests", func(t *testing.T) {
+
+		get := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/transactions", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		app.ServeHTTP(get, r)
+
+		head := httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodHead, "/v1/account/transactions", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		app.ServeHTTP(head, r)
+
+		for _, name := range []string{"Content-Encoding", "Content-Type", "ETag", "Vary"} {
+			if head.Header().Get(name) != get.Header().Get(name) {
+				t.Fatalf("unexpected %s: got %v want %v", name, head.Header().Get(name), get.Header().Get(name))
+			}
+		}
+		if head.Code != get.Code || head.Body.Len() != 0 {
+			t.Fatalf("unexpected response: got %v %v want %v %v", head.Code, head.Body.Len(), get.Code, 0)
+		}
+
+	})
+
+	t.Run("Should compress the response of a recovered panic with the body", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/statement", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		app.ServeHTTP(w, r)
+
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("unexpected error: got %v want %v", err, nil)
+		}
+		if w.Body.Len() != 0 {
+			t.Fatalf("unexpected trailing bytes: got %q want none", w.Body.Bytes())
+		}
+		if !strings.HasPrefix(string(body), strings.Repeat("statement ", 200)) || !strings.Contains(string(body), `"status":false`) {
+			t.Fatalf("unexpected body: got %q", body)
+		}
+
+	})
+}
+
+// go test -v -run TestResponseConditionalUnit ./...
+func TestResponseConditionalUnit(t *testing.T) {
+
+	quiet := false
+	app, err := New(typing.Augment{Logging: &quiet})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modified := time.Date(2026, time.October, 1, 9, 30, 0, 0, time.UTC)
+	app.Get("/v1/account/search", func(w http.ResponseWriter, r *http.Request) {
+		Response(w).Status(http.StatusOK).JSON(typing.Response{Status: true, Message: "account retrieved"})
+	})
+	app.Get("/v1/account/0123456789", func(w http.ResponseWriter, r *http.Request) {
+		Response(w).Status(http.StatusOK).ETag(`W/"v1"`).LastModified(modified).JSON(typing.Response{Status: true})
+	})
+	app.Patch("/v1/account/deposit", func(w http.ResponseWriter, r *http.Request) {
+		if Request(r).Preconditions(`"v1"`, modified) != 0 {
+			Response(w).Status(http.StatusPreconditionFailed).JSON(typing.Response{Message: "account was modified"})
+			return
+		}
+		Response(w).Status(http.StatusOK).ETag(`"v2"`).JSON(typing.Response{Status: true})
+	})
+
+	serve := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("Should tag responses with their body and answer 304 for the same tag", func(t *testing.T) {
+
+		w := serve(http.MethodGet, "/v1/account/search", nil)
+		tag := w.Header().Get("ETag")
+		if w.Code != http.StatusOK || tag == "" {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Header())
+		}
+
+		w = serve(http.MethodGet, "/v1/account/search", http.Header{"If-None-Match": {tag}})
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != tag {
+			t.Fatalf("unexpected response: got %v %q", w.Code, w.Body.String())
+		}
+
+	})
+
+	t.Run("Should use the given tag and modification time", func(t *testing.T) {
+
+		w := serve(http.MethodGet, "/v1/account/0123456789", http.Header{"If-Modified-Since": {modified.Format(http.TimeFormat)}})
+		if w.Code != http.StatusNotModified || w.Header().Get("ETag") != `W/"v1"` || w.Header().Get("Last-Modified") != modified.Format(http.TimeFormat) {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Header())
+		}
+
+		w = serve(http.MethodGet, "/v1/account/0123456789", http.Header{"If-Match": {`"v0"`}})
+		if w.Code != http.StatusPreconditionFailed {
+			t.Fatalf("unexpected status: got %v want %v", w.Code, http.StatusPreconditionFailed)
+		}
+
+	})
+
+	t.Run("Should let handlers check preconditions of mutations", func(t *testing.T) {
+
+		w := serve(http.MethodPatch, "/v1/account/deposit", http.Header{"If-Match": {`"v0"`}})
+		if w.Code != http.StatusPreconditionFailed {
+			t.Fatalf("unexpected status: got %v want %v", w.Code, http.StatusPreconditionFailed)
+		}
+
+		w = serve(http.MethodPatch, "/v1/account/deposit", http.Header{"If-Match": {`"v1"`}})
+		if w.Code != http.StatusOK || w.Header().Get("ETag") != `"v2"` {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Header())
+		}
+
+	})
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/opensaucerer/barf/router"
+	"github.com/opensaucerer/barf/typing"
+)
+
+/*
+Static serves the files of the given file system under the given prefix on the default router.
+Any fs.FS works, such as os.DirFS("public") or an embed.FS. Use fs.Sub to serve a directory of an embed.FS from its root.
+
+Content types are detected from file extensions, and range, If-Modified-Since and HEAD requests are handled.
+Paths are cleaned before they reach the file system so they cannot escape its root.
+*/
+func Static(prefix string, fsys fs.FS, options ...typing.Files) *router.Route {
+	return serveStatic(router.Default, prefix, fsys, options...)
+}
+
+// Static serves the files of the given file system under the given prefix. See barf.Static.
+func (a *App) Static(prefix string, fsys fs.FS, options ...typing.Files) *router.Route {
+	return serveStatic(a.Router, prefix, fsys, options...)
+}
+
+// serveStatic registers the static file handler on the given router
+func serveStatic(rt *router.Router, prefix string, fsys fs.FS, options ...typing.Files) *router.Route {
+	opts := typing.Files{Index: "index.html"}
+	if len(options) > 0 {
+		opts = options[0]
+		if opts.Index == "" {
+			opts.Index = "index.html"
+		}
+	}
+	return rt.Get(strings.TrimSuffix(prefix, "/")+"/*filepath", func(w http.ResponseWriter, r *http.Request) {
+		params, _ := r.Context().Value(typing.ParamsCtxKey{}).(map[string]string)
+		// clean the path so it can never escape the root of the file system
+		name := strings.TrimPrefix(path.Clean("/"+params["filepath"]), "/")
+		if name == "" {
+			name = "."
+		}
+		err := serveFile(w, r, fsys, name, opts)
+		if errors.Is(err, fs.ErrNotExist) && opts.SPA && path.Ext(name) == "" {
+			err = serveFile(w, r, fsys, opts.Index, opts)
+		}
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				JSON(w, false, http.StatusNotFound, fmt.Sprintf("File %s not found", r.URL.Path), nil)
+				return
+			}
+			JSON(w, false, http.StatusInternalServerError, "Internal Server Error: "+err.Error(), nil)
+		}
+	})
+}
+
+// serveFile writes the named file, or the index file of the named directory, to the response
+func serveFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string, opts typing.Files) error {
+	if !fs.ValidPath(name) {
+		return fs.ErrNotExist
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		// directories are never listed
+		return serveFile(w, r, fsys, path.Join(name, opts.Index), opts)
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		// not every fs.File can seek, which range requests need
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(data)
+	}
+	if opts.MaxAge > 0 {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(opts.MaxAge))
+	}
+	http.ServeContent(w, r, stat.Name(), stat.ModTime(), content)
+	return nil
+}
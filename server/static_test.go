@@ -0,0 +1,103 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// go test -v -run TestStaticUnit ./...
+func TestStaticUnit(t *testing.T) {
+
+	quiet := false
+	modified := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	dashboard := fstest.MapFS{
+		"index.html":    {Data: []byte("<html>dashboard</html>"), ModTime: modified},
+		"assets/app.js": {Data: []byte("console.log('zeina')"), ModTime: modified},
+	}
+
+	app, err := New(typing.Augment{Logging: &quiet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Static("/admin", dashboard, typing.Files{SPA: true})
+
+	serve := func(path string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("Should serve files with their content type", func(t *testing.T) {
+
+		w := serve("/admin/assets/app.js")
+		if w.Code != http.StatusOK || w.Body.String() != "console.log('zeina')" {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/javascript; charset=utf-8" {
+			t.Fatalf("unexpected content type: got %v", ct)
+		}
+
+	})
+
+	t.Run("Should serve the index file for the prefix", func(t *testing.T) {
+
+		w := serve("/admin")
+		if w.Code != http.StatusOK || w.Body.String() != "<html>dashboard</html>" {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Body.String())
+		}
+
+	})
+
+	t.Run("Should serve range requests", func(t *testing.T) {
+
+		w := serve("/admin/assets/app.js", "Range", "bytes=0-6")
+		if w.Code != http.StatusPartialContent || w.Body.String() != "console" {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Body.String())
+		}
+
+	})
+
+	t.Run("Should honour If-Modified-Since", func(t *testing.T) {
+
+		w := serve("/admin/assets/app.js", "If-Modified-Since", modified.Format(http.TimeFormat))
+		if w.Code != http.StatusNotModified {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusNotModified)
+		}
+
+	})
+
+	t.Run("Should fall back to the index file for app routes only", func(t *testing.T) {
+
+		w := serve("/admin/accounts/0123456789")
+		if w.Code != http.StatusOK || w.Body.String() != "<html>dashboard</html>" {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Body.String())
+		}
+
+		w = serve("/admin/assets/missing.js")
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusNotFound)
+		}
+
+	})
+
+	t.Run("Should not escape the root of the file system", func(t *testing.T) {
+
+		r := httptest.NewRequest(http.MethodGet, "/admin/assets/app.js", nil)
+		r.URL.Path = "/admin/../../../etc/passwd.txt"
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("unexpected status code: got %v want %v", w.Code, http.StatusNotFound)
+		}
+
+	})
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opensaucerer/barf/typing"
+	"github.com/opensaucerer/barf/ws"
+)
+
+// go test -v -run TestWebSocketRouteUnit ./...
+func TestWebSocketRouteUnit(t *testing.T) {
+
+	quiet := false
+	app, err := New(typing.Augment{Logging: &quiet})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the app token check of the zeina app
+	Hippocampus(app).Hijack(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if Request(r).Header().Get("zeina-mfi") != "token" {
+				Response(w).Status(http.StatusUnauthorized).JSON(nil)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	})
+	app.WS("/v1/account/:number/stream", func(c *ws.Conn) {
+		params, _ := Request(c.Request()).Params().JSON()
+		c.WriteMessage(ws.TextMessage, []byte(params["number"]))
+	})
+
+	s := httptest.NewServer(app)
+	defer s.Close()
+
+	// handshake opens a connection and sends the handshake with the given app token
+	handshake := func(token string) (net.Conn, *bufio.Reader, *http.Response) {
+		conn, err := net.Dial("tcp", s.Listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, _ := http.NewRequest(http.MethodGet, s.URL+"/v1/account/0123456789/stream", nil)
+		r.Header.Set("Connection", "Upgrade")
+		r.Header.Set("Upgrade", "websocket")
+		r.Header.Set("Sec-WebSocket-Version", "13")
+		r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		r.Header.Set("zeina-mfi", token)
+		r.Write(conn)
+		br := bufio.NewReader(conn)
+		res, err := http.ReadResponse(br, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn, br, res
+	}
+
+	t.Run("Should run the global middleware before upgrading", func(t *testing.T) {
+
+		conn, _, res := handshake("")
+		defer conn.Close()
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("unexpected status: got %v want %v", res.StatusCode, http.StatusUnauthorized)
+		}
+
+	})
+
+	t.Run("Should upgrade routes registered with WS", func(t *testing.T) {
+
+		conn, br, res := handshake("token")
+		defer conn.Close()
+		if res.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("unexpected status: got %v want %v", res.StatusCode, http.StatusSwitchingProtocols)
+		}
+
+		// an unmasked text frame holding the param followed by the close frame
+		frame := make([]byte, 12)
+		if _, err := io.ReadFull(br, frame); err != nil {
+			t.Fatal(err)
+		}
+		if frame[0] != 0x81 || frame[1] != 10 || string(frame[2:]) != "0123456789" {
+			t.Fatalf("unexpected frame: got %q", frame)
+		}
+		close := make([]byte, 4)
+		if _, err := io.ReadFull(br, close); err != nil || close[0] != 0x88 || close[2] != 0x03 || close[3] != 0xe8 {
+			t.Fatalf("unexpected close frame: got %v %v", close, err)
+		}
+
+	})
+
+	t.Run("Should answer plain requests with a JSON error", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/0123456789/stream", nil)
+		r.Header.Set("zeina-mfi", "token")
+		app.ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") != "application/json" {
+			t.Fatalf("unexpected response: got %v %v", w.Code, w.Body.String())
+		}
+
+	})
+}
@@ -0,0 +1,13 @@
+//go:build go1.20
+
+package sse
+
+import (
+	"net/http"
+	"time"
+)
+
+// unlimit clears the write deadline of the connection the response is written to, if the writer supports it
+func unlimit(w http.ResponseWriter) {
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+}
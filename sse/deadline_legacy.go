@@ -0,0 +1,8 @@
+//go:build !go1.20
+
+package sse
+
+import "net/http"
+
+// unlimit is a no-op before go1.20. Streams are then cut off by barf.Augment.WriteTimeout, which a negative value disables.
+func unlimit(w http.ResponseWriter) {}
@@ -0,0 +1,210 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Heartbeat is the default interval comments are sent at to keep idle streams open
+const Heartbeat = 15 * time.Second
+
+// ErrClosed is returned when sending on a stream that was closed, or whose client went away
+var ErrClosed = errors.New("barf: stream is closed")
+
+// ErrUnsupported is returned when the response writer cannot flush, so events could not reach the client as they are sent
+var ErrUnsupported = errors.New("barf: streaming is not supported by the response writer")
+
+// Event is a single server-sent event
+type Event struct {
+	// ID is the id of the event. Clients send the id of the last event they received
+	// in the Last-Event-ID header when they reconnect.
+	ID string
+	// Event is the name of the event. Clients receive unnamed events as "message".
+	Event string
+	// Data is the payload of the event. Strings and bytes are sent as is, anything else as JSON.
+	Data interface{}
+	// Retry tells the client how long to wait before reconnecting
+	Retry time.Duration
+}
+
+// Stream is an open server-sent events stream
+type Stream struct {
+	mu      sync.Mutex
+	writer  http.ResponseWriter
+	flusher http.Flusher
+	// last is the Last-Event-ID the client reconnected with
+	last   string
+	done   chan struct{}
+	closed bool
+}
+
+/*
+Open starts an event stream on the given response writer. The stream is closed when the client goes away, when
+the request is served or when Close is called. A comment is sent at every heartbeat to keep idle connections open.
+*/
+func Open(w http.ResponseWriter, r *http.Request, opts typing.SSE) (*Stream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	s := &Stream{
+		writer:  w,
+		flusher: flusher,
+		done:    make(chan struct{}),
+	}
+
+	// a nil channel never fires, i.e for streams opened outside of a request
+	var gone <-chan struct{}
+	if r != nil {
+		s.last = r.Header.Get("Last-Event-ID")
+		gone = r.Context().Done()
+	}
+
+	// streams outlive the write timeout of the server
+	unlimit(w)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// keep reverse proxies such as nginx from buffering the events
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if opts.Retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n\n", opts.Retry.Milliseconds())
+	}
+	flusher.Flush()
+
+	heartbeat := opts.Heartbeat
+	if heartbeat == 0 {
+		heartbeat = Heartbeat
+	}
+	go s.watch(gone, heartbeat)
+
+	return s, nil
+}
+
+// LastEventID returns the id of the last event the client received before reconnecting, if any
+func (s *Stream) LastEventID() string {
+	return s.last
+}
+
+// Done returns a channel that is closed once the stream is closed
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Send writes the given event to the client and flushes it
+func (s *Stream) Send(e Event) error {
+	var buf bytes.Buffer
+	if e.ID != "" {
+		buf.WriteString("id: " + line(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		buf.WriteString("event: " + line(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	data, err := payload(e.Data)
+	if err != nil {
+		return err
+	}
+	for _, l := range strings.Split(data, "\n") {
+		buf.WriteString("data: " + strings.TrimSuffix(l, "\r") + "\n")
+	}
+	buf.WriteString("\n")
+	return s.write(buf.Bytes())
+}
+
+// Comment writes a comment to the client. Clients ignore comments, which makes them useful to keep connections open.
+func (s *Stream) Comment(text string) error {
+	var buf bytes.Buffer
+	for _, l := range strings.Split(text, "\n") {
+		buf.WriteString(": " + strings.TrimSuffix(l, "\r") + "\n")
+	}
+	buf.WriteString("\n")
+	return s.write(buf.Bytes())
+}
+
+// Close closes the stream. Nothing is written to the response writer afterwards.
+func (s *Stream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+}
+
+// write writes and flushes the given bytes unless the stream is closed. The stream is closed when the write fails.
+func (s *Stream) write(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if _, err := s.writer.Write(p); err != nil {
+		s.closed = true
+		close(s.done)
+		return ErrClosed
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// watch sends heartbeats until the stream is closed and closes it once the client is gone
+func (s *Stream) watch(gone <-chan struct{}, heartbeat time.Duration) {
+	var tick <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-gone:
+			s.Close()
+			return
+		case <-s.done:
+			return
+		case <-tick:
+			if s.Comment("heartbeat") != nil {
+				return
+			}
+		}
+	}
+}
+
+// payload returns the data of an event as text
+func payload(data interface{}) (string, error) {
+	switch d := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return d, nil
+	case []byte:
+		return string(d), nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// line strips the line breaks that would end a field early
+func line(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,119 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// recorder is a goroutine safe httptest.ResponseRecorder such that heartbeats can be read while they are written
+type recorder struct {
+	mu sync.Mutex
+	*httptest.ResponseRecorder
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ResponseRecorder.Write(p)
+}
+
+func (r *recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Body.String()
+}
+
+// go test -v -run TestStreamUnit ./...
+func TestStreamUnit(t *testing.T) {
+
+	t.Run("Should set the event stream headers", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		s, err := Open(w, httptest.NewRequest(http.MethodGet, "/v1/account/transactions/feed", nil), typing.SSE{Retry: 3 * time.Second})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer s.Close()
+
+		if w.Header().Get("Content-Type") != "text/event-stream" || w.Header().Get("Cache-Control") != "no-cache" {
+			t.Fatalf("unexpected headers: got %v", w.Header())
+		}
+		if !w.Flushed || w.Body.String() != "retry: 3000\n\n" {
+			t.Fatalf("unexpected body: got %q", w.Body.String())
+		}
+
+	})
+
+	t.Run("Should write events", func(t *testing.T) {
+
+		w := httptest.NewRecorder()
+		s, _ := Open(w, nil, typing.SSE{Heartbeat: -1})
+		defer s.Close()
+
+		s.Send(Event{ID: "A1", Event: "transaction", Data: map[string]interface{}{"amount": 2500}})
+		s.Send(Event{Data: "first\nsecond"})
+		s.Comment("ping")
+
+		want := "id: A1\nevent: transaction\ndata: {\"amount\":2500}\n\n" +
+			"data: first\ndata: second\n\n" +
+			": ping\n\n"
+		if w.Body.String() != want {
+			t.Fatalf("unexpected body: got %q want %q", w.Body.String(), want)
+		}
+
+	})
+
+	t.Run("Should read the last event id", func(t *testing.T) {
+
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/transactions/feed", nil)
+		r.Header.Set("Last-Event-ID", "A1")
+		s, _ := Open(httptest.NewRecorder(), r, typing.SSE{})
+		defer s.Close()
+
+		if s.LastEventID() != "A1" {
+			t.Fatalf("unexpected last event id: got %v want %v", s.LastEventID(), "A1")
+		}
+
+	})
+
+	t.Run("Should send heartbeats", func(t *testing.T) {
+
+		w := &recorder{ResponseRecorder: httptest.NewRecorder()}
+		s, _ := Open(w, nil, typing.SSE{Heartbeat: 5 * time.Millisecond})
+		defer s.Close()
+
+		deadline := time.Now().Add(time.Second)
+		for !strings.Contains(w.String(), ": heartbeat\n\n") {
+			if time.Now().After(deadline) {
+				t.Fatal("expected a heartbeat but got none")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+	})
+
+	t.Run("Should close once the client is gone", func(t *testing.T) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		r := httptest.NewRequest(http.MethodGet, "/v1/account/transactions/feed", nil).WithContext(ctx)
+		s, _ := Open(httptest.NewRecorder(), r, typing.SSE{})
+		cancel()
+
+		select {
+		case <-s.Done():
+		case <-time.After(time.Second):
+			t.Fatal("expected the stream to close")
+		}
+		if err := s.Send(Event{Data: "late"}); err != ErrClosed {
+			t.Fatalf("unexpected error: got %v want %v", err, ErrClosed)
+		}
+
+	})
+}
@@ -1,6 +1,10 @@
 package typing
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+	"time"
+)
 
 // Augment holds refrence to all of barf's config
 type Augment struct {
@@ -9,6 +13,10 @@ type Augment struct {
 	// request line. It does not limit the size of the request body.
 	// default is 1 << 20 (1 MB)
 	MaxHeaderBytes int
+	// MaxBodySize is the maximum number of bytes the server will read from a request body.
+	// Requests with a larger body are answered with a 413. A negative value disables the limit.
+	// default is 10 << 20 (10 MB)
+	MaxBodySize int64
 	// ReadTimeout is the maximum duration in seconds for reading the entire
 	// request, including the body.
 	// default is 10 seconds
@@ -35,11 +43,47 @@ type Augment struct {
 	// Logging is for defining whether or not to enable request logging
 	// default is true
 	Logging *bool
+	// LogFormat is the format requests are logged in, one line per request. It is either text or json.
+	// default is text
+	LogFormat string
+	// LogOutput is where requests are logged to. Text lines are only colored when it is not set.
+	// default is the output of the standard log package
+	LogOutput io.Writer
 	// Recovery is for defining whether or not to enable panic recovery
 	// default is true
 	Recovery *bool
 	// CORS is the configuration for Cross-Origin Resource Sharing
 	CORS *CORS
+	// StrictRouting makes route registration panic on shadowed routes instead of logging a warning.
+	// Duplicate and ambiguous routes always panic.
+	// default is false
+	StrictRouting bool
+	// Debug prints the route table when the server starts and serves it as JSON
+	// with GET /barf/routes
+	// default is false
+	Debug bool
+	// Versioning configures how the API version of a request is negotiated
+	// for routes registered with barf.Version
+	Versioning *Versioning
+	// TrustedProxies is the list of IP addresses and CIDR ranges of the proxies allowed to report
+	// the client IP through the Forwarded and X-Forwarded-For headers i.e 10.0.0.0/8
+	// default is none (the forwarding headers are ignored)
+	TrustedProxies []string
+	// CookieSecret is the secret signed cookies are signed and verified with
+	// default is "" (signed cookies are rejected)
+	CookieSecret string
+	// WebSocket configures the connections of routes registered with barf.WS
+	WebSocket *WebSocket
+	// Compression compresses responses with gzip or deflate for requests accepting it
+	// default is nil (responses are not compressed)
+	Compression *Compression
+	// ErrorHandler maps the errors answered with barf.Response(w).Error that are neither a barf.Error nor an error of barf itself,
+	// such as the errors of the logic layer. Errors it returns nil for are answered with a 500.
+	ErrorHandler func(err error) *Error
+	// ProblemDetails answers errors with an application/problem+json body (RFC 7807) rather than a barf.Res envelope.
+	// Requests accepting application/problem+json get one regardless.
+	// default is false
+	ProblemDetails bool
 }
 
 // CORS holds configuration for Cross-Origin Resource Sharing
@@ -71,3 +115,109 @@ type CORS struct {
 	// AllowedOriginWithRequestFunc is a callback for handling user defined origin checks with access to the http request object.
 	AllowedOriginWithRequestFunc func(origin string, r *http.Request) bool
 }
+
+// Files holds configuration for serving static files
+type Files struct {
+	// Index is the file served for a directory
+	// default is index.html
+	Index string
+	// SPA serves the index file of the root directory for paths without a file extension
+	// that do not match a file, such that a single page app can handle its own routing
+	// default is false
+	SPA bool
+	// MaxAge is the number of seconds clients may cache the served files for, sent in the Cache-Control header
+	// default is 0 (no Cache-Control header)
+	MaxAge int
+}
+
+// Versioning holds configuration for API version negotiation
+type Versioning struct {
+	// Default is the version served when a request does not ask for one.
+	// Requests without a version fall back to unversioned routes when no route is registered for the default.
+	Default string
+	// Header is the header the version is requested with and announced in on responses
+	// default is API-Version
+	Header string
+	// Vendor restricts versions requested through the Accept header to the given vendor
+	// i.e zeina for application/vnd.zeina.v2+json
+	// default is "" (any vendor)
+	Vendor string
+	// Deprecated holds the deprecated versions along with their sunset time. Responses served by a
+	// deprecated version carry a Deprecation header and, unless the time is zero, a Sunset header.
+	Deprecated map[string]time.Time
+}
+
+// Uploads holds configuration for receiving multipart file uploads with barf.Request(r).Files
+type Uploads struct {
+	// MaxFileSize is the maximum number of bytes of a single file
+	// default is 0 (only barf.Augment.MaxBodySize applies)
+	MaxFileSize int64
+	// MaxTotalSize is the maximum number of bytes of all files and values together
+	// default is 0 (only barf.Augment.MaxBodySize applies)
+	MaxTotalSize int64
+	// Allowed is the list of content types files may have, as sniffed from their content
+	// rather than declared by the client i.e image/png or image/*
+	// default is none (any content type)
+	Allowed []string
+	// Dir is the directory files are streamed to when no Storage is given
+	// default is the temporary directory of the OS
+	Dir string
+	// Storage stores the uploaded files instead of the temporary directory
+	Storage Storage
+}
+
+// Storage stores uploaded files. Files are removed from it once the request is served unless they were kept.
+type Storage interface {
+	// Save stores the content of the file with the given name and returns the key it can be opened and removed with
+	Save(name string, content io.Reader) (string, error)
+	// Open opens the file stored with the given key
+	Open(key string) (io.ReadCloser, error)
+	// Remove removes the file stored with the given key
+	Remove(key string) error
+}
+
+// SSE holds configuration for server-sent event streams opened with barf.Response(w).Stream
+type SSE struct {
+	// Heartbeat is the interval a comment is sent at to keep idle streams open through proxies.
+	// A negative value disables heartbeats.
+	// default is 15 seconds
+	Heartbeat time.Duration
+	// Retry tells clients how long to wait before reconnecting once the stream is lost
+	// default is 0 (the client decides)
+	Retry time.Duration
+}
+
+// WebSocket holds configuration for WebSocket connections
+type WebSocket struct {
+	// MaxMessageSize is the maximum number of bytes of a received message. Connections sending a larger one
+	// are closed with 1009 (message too big). Messages can not be unlimited, zero and negative values use the default.
+	// default is 1 << 20 (1 MB)
+	MaxMessageSize int64
+	// PingInterval is the interval connections are pinged at. Connections that send nothing, not even a pong,
+	// for twice the interval are considered gone. A negative value disables pings.
+	// default is 30 seconds
+	PingInterval time.Duration
+	// WriteTimeout is the time allowed to write a frame, pings and close frames included. Connections that can not
+	// be written to in time fail the write. A negative value disables the timeout.
+	// default is 10 seconds
+	WriteTimeout time.Duration
+	// Subprotocols is the list of subprotocols the server speaks, in order of preference
+	Subprotocols []string
+	// CheckOrigin reports whether a connection may be opened from the origin of the request.
+	// default only allows requests without an Origin header or from the host they are sent to
+	CheckOrigin func(r *http.Request) bool
+}
+
+// Compression holds configuration for compressing responses
+type Compression struct {
+	// Level is the compression level, from 1 (best speed) to 9 (best compression)
+	// default is 0 (the default level of compress/flate)
+	Level int
+	// MinSize is the minimum number of bytes of a response body for it to be compressed.
+	// Flushed responses such as event streams are compressed regardless.
+	// default is 1024
+	MinSize int
+	// ContentTypes is the list of content types that are compressed i.e application/json or text/*
+	// default is text/*, application/json, application/xml, application/javascript, image/svg+xml and any +json or +xml type
+	ContentTypes []string
+}
@@ -9,9 +9,9 @@ type Health struct {
 }
 
 type Response struct {
-	Status  bool        `json:"status"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+	Status  bool        `json:"status" xml:"status"`
+	Message string      `json:"message" xml:"message"`
+	Data    interface{} `json:"data" xml:"data"`
 }
 
 type M map[string]string
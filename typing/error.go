@@ -0,0 +1,57 @@
+package typing
+
+import (
+	"net/http"
+	"strings"
+)
+
+// FieldError describes why the value of a single field was rejected
+type FieldError struct {
+	// Field is the name of the field as given by its tags
+	Field string `json:"field"`
+	// Message explains why the value was rejected i.e email is required
+	Message string `json:"message"`
+}
+
+// FieldErrors is the list of fields rejected while binding or validating a request
+type FieldErrors []FieldError
+
+// Error joins the messages of every field error
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, f := range e {
+		messages[i] = f.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Error is an error carrying the HTTP status it should be answered with, along with a machine-readable code,
+// the fields it concerns and its cause
+type Error struct {
+	// Status is the HTTP status the error is answered with
+	Status int
+	// Code identifies the error for clients i.e account_not_found
+	Code string
+	// Message explains the error to clients. The status text is used when empty.
+	Message string
+	// Type is a URI identifying the type of problem in problem+json responses
+	// default is about:blank
+	Type string
+	// Fields holds the fields the error concerns, if any
+	Fields FieldErrors
+	// Cause is the error that caused this one. It is logged but never sent to clients.
+	Cause error
+}
+
+// Error returns the message of the error
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return http.StatusText(e.Status)
+}
+
+// Unwrap returns the cause of the error such that errors.Is and errors.As can reach it
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
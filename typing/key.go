@@ -11,3 +11,15 @@ type StatusCodeCtxKey struct{}
 
 // ParamsCtxKey is the key for the path params in the context
 type ParamsCtxKey struct{}
+
+// AugmentCtxKey is the key for the config of the app serving the request in the context
+type AugmentCtxKey struct{}
+
+// ProxiesCtxKey is the key for the trusted proxies of the app serving the request, as parsed once the app is created, in the context
+type ProxiesCtxKey struct{}
+
+// CleanupCtxKey is the key for the functions run once the request is served in the context
+type CleanupCtxKey struct{}
+
+// RequestIDCtxKey is the key for the ID of the request in the context
+type RequestIDCtxKey struct{}
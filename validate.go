@@ -0,0 +1,33 @@
+package barf
+
+import "github.com/opensaucerer/barf/validate"
+
+/*
+Validate validates the fields of the given struct against the rules of their `validate` tags and returns barf.FieldErrors listing every rejected field.
+
+	type User struct {
+		Email string `json:"email" validate:"required,email"`
+		Age   int    `json:"age" validate:"min=7"`
+	}
+
+Structs formatted with barf.Request(r).Body().Format and barf.Request(r).Query().Format are validated automatically.
+*/
+var Validate = validate.Struct
+
+/*
+Validator registers a custom validation rule under the given name such that it can be used in validate tags.
+
+	barf.Validator("account", func(f barf.RuleField) error {
+		if len(f.Value.String()) != 10 {
+			return errors.New("must be a 10 digit account number")
+		}
+		return nil
+	})
+*/
+var Validator = validate.Register
+
+// Rule checks a field and returns an error describing why it was rejected, if it was
+type Rule = validate.Rule
+
+// RuleField is the field a validation rule is checked against
+type RuleField = validate.Field
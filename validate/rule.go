@@ -0,0 +1,187 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/opensaucerer/barf/helper"
+)
+
+// timeType is the type of time.Time which is compared as a value rather than validated as a struct
+var timeType = reflect.TypeOf(time.Time{})
+
+// patterns caches the compiled regular expressions of regex rules
+var patterns sync.Map
+
+func init() {
+	rules["required"] = required
+	rules["min"] = bound("must be at least", func(a, b float64) bool { return a >= b })
+	rules["max"] = bound("must be at most", func(a, b float64) bool { return a <= b })
+	rules["len"] = bound("must be exactly", func(a, b float64) bool { return a == b })
+	rules["email"] = email
+	rules["oneof"] = oneof
+	rules["regex"] = match
+	rules["eqfield"] = equal("must be equal to", true)
+	rules["nefield"] = equal("must not be equal to", false)
+	rules["gtfield"] = compare("must be greater than", func(c int) bool { return c > 0 })
+	rules["gtefield"] = compare("must be greater than or equal to", func(c int) bool { return c >= 0 })
+	rules["ltfield"] = compare("must be less than", func(c int) bool { return c < 0 })
+	rules["ltefield"] = compare("must be less than or equal to", func(c int) bool { return c <= 0 })
+}
+
+// required rejects zero values
+func required(f Field) error {
+	if f.Value.IsZero() {
+		return errors.New("is required")
+	}
+	return nil
+}
+
+// bound creates a rule comparing a number, or the length of a string, slice or map, with the param of the rule
+func bound(message string, ok func(value, param float64) bool) Rule {
+	return func(f Field) error {
+		param, err := strconv.ParseFloat(f.Param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("barf: invalid validation param %q on field %s", f.Param, f.Name))
+		}
+		v := indirect(f.Value)
+		var value float64
+		unit := ""
+		switch v.Kind() {
+		case reflect.String:
+			value, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			value, unit = float64(v.Len()), " items"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			value = v.Float()
+		case reflect.Ptr:
+			// a nil pointer has nothing to bound
+			return nil
+		default:
+			panic(fmt.Sprintf("barf: cannot bound field %s of type %s", f.Name, v.Type()))
+		}
+		if !ok(value, param) {
+			return fmt.Errorf("%s %s%s", message, f.Param, unit)
+		}
+		return nil
+	}
+}
+
+// email rejects strings that are not a bare email address
+func email(f Field) error {
+	s := indirect(f.Value).String()
+	address, err := mail.ParseAddress(s)
+	if err != nil || address.Address != s {
+		return errors.New("must be a valid email address")
+	}
+	return nil
+}
+
+// oneof rejects values that are not one of the space separated values of the param
+func oneof(f Field) error {
+	value := fmt.Sprint(indirect(f.Value).Interface())
+	for _, allowed := range strings.Fields(f.Param) {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(strings.Fields(f.Param), ", "))
+}
+
+// match rejects strings that do not match the regular expression of the param
+func match(f Field) error {
+	var re *regexp.Regexp
+	if cached, ok := patterns.Load(f.Param); ok {
+		re = cached.(*regexp.Regexp)
+	} else {
+		compiled, err := regexp.Compile(f.Param)
+		if err != nil {
+			panic(fmt.Sprintf("barf: invalid validation pattern %s on field %s: %s", f.Param, f.Name, err))
+		}
+		patterns.Store(f.Param, compiled)
+		re = compiled
+	}
+	if !re.MatchString(fmt.Sprint(indirect(f.Value).Interface())) {
+		return fmt.Errorf("must match %s", f.Param)
+	}
+	return nil
+}
+
+// sibling returns the field of the struct holding f named by the param of the rule, along with its name as given by its json tag
+func sibling(f Field) (reflect.Value, string) {
+	other, found := f.Parent.Type().FieldByName(f.Param)
+	if !found {
+		panic(fmt.Sprintf("barf: unknown field %s compared with field %s", f.Param, f.Name))
+	}
+	name := helper.Name(other, "json")
+	if name == "" {
+		name = other.Name
+	}
+	return indirect(f.Parent.FieldByIndex(other.Index)), name
+}
+
+// equal creates a rule checking whether a field is equal to the field of the same struct named by the param of the rule
+func equal(message string, want bool) Rule {
+	return func(f Field) error {
+		other, name := sibling(f)
+		if reflect.DeepEqual(indirect(f.Value).Interface(), other.Interface()) != want {
+			return fmt.Errorf("%s %s", message, label(name))
+		}
+		return nil
+	}
+}
+
+// compare creates a rule ordering a field against the field of the same struct named by the param of the rule
+func compare(message string, ok func(c int) bool) Rule {
+	return func(f Field) error {
+		other, name := sibling(f)
+		c, ordered := order(indirect(f.Value), other)
+		if !ordered || !ok(c) {
+			return fmt.Errorf("%s %s", message, label(name))
+		}
+		return nil
+	}
+}
+
+// order compares a with b and returns -1, 0 or 1 along with whether the values could be ordered at all
+func order(a, b reflect.Value) (int, bool) {
+	if a.Type() != b.Type() {
+		return 0, false
+	}
+	sign := func(less, greater bool) int {
+		switch {
+		case less:
+			return -1
+		case greater:
+			return 1
+		}
+		return 0
+	}
+	switch a.Kind() {
+	case reflect.String:
+		return sign(a.String() < b.String(), a.String() > b.String()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return sign(a.Int() < b.Int(), a.Int() > b.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return sign(a.Uint() < b.Uint(), a.Uint() > b.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return sign(a.Float() < b.Float(), a.Float() > b.Float()), true
+	}
+	if a.Type() == timeType {
+		x, y := a.Interface().(time.Time), b.Interface().(time.Time)
+		return sign(x.Before(y), x.After(y)), true
+	}
+	return 0, false
+}
@@ -0,0 +1,177 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/opensaucerer/barf/helper"
+	"github.com/opensaucerer/barf/typing"
+)
+
+// Field is the field a rule is checked against
+type Field struct {
+	// Name is the name of the field as given by its json tag
+	Name string
+	// Value is the value of the field
+	Value reflect.Value
+	// Param is the parameter of the rule i.e 7 for min=7
+	Param string
+	// Parent is the struct holding the field, for rules comparing fields with each other
+	Parent reflect.Value
+}
+
+// Rule checks a field and returns an error describing why it was rejected, if it was.
+// The message of the error follows the label of the field i.e "is required" reads as "email is required".
+type Rule func(f Field) error
+
+var (
+	// mu guards rules
+	mu sync.RWMutex
+	// rules holds the built-in and custom rules by name
+	rules = map[string]Rule{}
+)
+
+// Register adds a custom rule that can be used in validate tags under the given name. It replaces any rule with the same name.
+func Register(name string, rule Rule) {
+	mu.Lock()
+	defer mu.Unlock()
+	rules[name] = rule
+}
+
+// lookup returns the rule with the given name
+func lookup(name string) (Rule, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	rule, ok := rules[name]
+	return rule, ok
+}
+
+/*
+Struct validates the fields of the struct v points to against the rules of their `validate` tag and returns typing.FieldErrors listing every rejected field.
+
+	type User struct {
+		Email    string `json:"email" validate:"required,email"`
+		Age      int    `json:"age" validate:"min=7,max=120"`
+		Type     string `json:"type" validate:"omitempty,oneof=savings current"`
+		Password string `json:"password" validate:"required,len=8"`
+		Confirm  string `json:"confirm" validate:"eqfield=Password"`
+	}
+
+The following rules are built in and custom rules are added with Register
+
+	required                      the field is not the zero value
+	omitempty                     skip the remaining rules when the field is the zero value
+	min=n, max=n, len=n           bounds of a number, or of the length of a string, slice or map
+	email                         a valid email address
+	oneof=a b c                   one of the space separated values
+	regex=pattern                 matches the regular expression, commas must be escaped as \,
+	eqfield=F, nefield=F          equal or not equal to the field F of the same struct
+	gtfield=F, gtefield=F         greater than (or equal to) the field F of the same struct
+	ltfield=F, ltefield=F         less than (or equal to) the field F of the same struct
+
+Embedded structs are validated as if their fields belonged to v, and struct fields carrying a validate tag of their own are validated in turn.
+Fields are named by their json tag, falling back to the field name.
+*/
+func Struct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot validate %T, expected a struct", v)
+	}
+	errs := check(rv, "", typing.FieldErrors{})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// check validates the fields of the struct value rv and returns errs along with the rejected fields.
+// The names of the fields are prefixed with the given prefix.
+func check(rv reflect.Value, prefix string, errs typing.FieldErrors) typing.FieldErrors {
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, tagged := f.Tag.Lookup("validate")
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			errs = check(rv.Field(i), prefix, errs)
+			continue
+		}
+		if !tagged || tag == "-" {
+			continue
+		}
+		name := helper.Name(f, "json")
+		if name == "" {
+			name = f.Name
+		}
+		name = prefix + name
+		field := rv.Field(i)
+		rejected := false
+		for _, r := range split(tag) {
+			rname, param, _ := strings.Cut(r, "=")
+			if rname == "" {
+				continue
+			}
+			if rname == "omitempty" {
+				if field.IsZero() {
+					break
+				}
+				continue
+			}
+			rule, ok := lookup(rname)
+			if !ok {
+				panic(fmt.Sprintf("barf: unknown validation rule %s on field %s", rname, f.Name))
+			}
+			if err := rule(Field{Name: name, Value: field, Param: param, Parent: rv}); err != nil {
+				errs = append(errs, typing.FieldError{Field: name, Message: label(name) + " " + err.Error()})
+				rejected = true
+				break
+			}
+		}
+		// validate the fields of nested structs that were not rejected themselves
+		if nested := indirect(field); !rejected && nested.Kind() == reflect.Struct && nested.Type() != timeType {
+			errs = check(nested, name+".", errs)
+		}
+	}
+	return errs
+}
+
+// label returns the human readable form of a field name i.e first name for first_name
+func label(name string) string {
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+// split splits a tag into its rules on commas that are not escaped with a backslash
+func split(tag string) []string {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			part.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(tag[i])
+		}
+	}
+	return append(parts, part.String())
+}
+
+// indirect dereferences v until it is not a non-nil pointer
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,112 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// teller mirrors the shape of a user of the zeina app
+type teller struct {
+	FirstName string    `json:"first_name" validate:"required"`
+	Email     string    `json:"email" validate:"required,email"`
+	Age       int       `json:"age" validate:"min=7,max=120"`
+	Number    string    `json:"number" validate:"omitempty,len=10,regex=^[0-9]+$"`
+	Type      string    `json:"type" validate:"oneof=savings current"`
+	Tags      []string  `json:"tags" validate:"max=2"`
+	Password  string    `json:"password" validate:"required"`
+	Confirm   string    `json:"confirm" validate:"eqfield=Password"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to" validate:"gtfield=From"`
+	Branch    *branch   `json:"branch" validate:"required"`
+}
+
+type branch struct {
+	Code string `json:"code" validate:"required,branch"`
+}
+
+// valid returns a teller passing every rule
+func valid() teller {
+	return teller{
+		FirstName: "John",
+		Email:     "johndoe@email.com",
+		Age:       30,
+		Type:      "savings",
+		Password:  "secret",
+		Confirm:   "secret",
+		From:      time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+		Branch:    &branch{Code: "LOS"},
+	}
+}
+
+// go test -v -run TestValidateUnit ./...
+func TestValidateUnit(t *testing.T) {
+
+	Register("branch", func(f Field) error {
+		if len(f.Value.String()) != 3 {
+			return errors.New("must be a 3 letter branch code")
+		}
+		return nil
+	})
+
+	t.Run("Should accept valid structs", func(t *testing.T) {
+
+		data := valid()
+		if err := Struct(&data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+	})
+
+	cases := []struct {
+		name    string
+		change  func(*teller)
+		field   string
+		message string
+	}{
+		{"Should require fields", func(d *teller) { d.FirstName = "" }, "first_name", "first name is required"},
+		{"Should check email addresses", func(d *teller) { d.Email = "John <johndoe@email.com>" }, "email", "email must be a valid email address"},
+		{"Should check numeric bounds", func(d *teller) { d.Age = 6 }, "age", "age must be at least 7"},
+		{"Should check lengths", func(d *teller) { d.Number = "012345678" }, "number", "number must be exactly 10 characters"},
+		{"Should check patterns", func(d *teller) { d.Number = "012345678a" }, "number", "number must match ^[0-9]+$"},
+		{"Should check allowed values", func(d *teller) { d.Type = "checking" }, "type", "type must be one of savings, current"},
+		{"Should check the length of slices", func(d *teller) { d.Tags = []string{"a", "b", "c"} }, "tags", "tags must be at most 2 items"},
+		{"Should compare fields with each other", func(d *teller) { d.Confirm = "secrets" }, "confirm", "confirm must be equal to password"},
+		{"Should order fields against each other", func(d *teller) { d.To = d.From }, "to", "to must be greater than from"},
+		{"Should validate nested structs with custom rules", func(d *teller) { d.Branch.Code = "LAGOS" }, "branch.code", "branch.code must be a 3 letter branch code"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			data := valid()
+			c.change(&data)
+			err := Struct(&data)
+			var fields typing.FieldErrors
+			if !errors.As(err, &fields) || len(fields) != 1 {
+				t.Fatalf("unexpected error: got %v", err)
+			}
+			if !reflect.DeepEqual(fields[0], typing.FieldError{Field: c.field, Message: c.message}) {
+				t.Fatalf("unexpected field error: got %v want %v", fields[0], typing.FieldError{Field: c.field, Message: c.message})
+			}
+
+		})
+	}
+
+	t.Run("Should report every rejected field", func(t *testing.T) {
+
+		err := Struct(&teller{})
+		var fields typing.FieldErrors
+		if !errors.As(err, &fields) {
+			t.Fatalf("unexpected error: got %v", err)
+		}
+		if len(fields) != 7 {
+			t.Fatalf("unexpected number of field errors: got %v want %v", fields, 7)
+		}
+
+	})
+}
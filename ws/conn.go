@@ -0,0 +1,424 @@
+package ws
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// message types, as the opcodes of their frames
+const (
+	continuation  = 0
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// close codes of RFC 6455 section 7.4.1
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseAbnormal        = 1006
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+)
+
+// ErrClosed is returned when writing to a connection that was closed
+var ErrClosed = errors.New("barf: websocket connection is closed")
+
+// CloseError is returned by ReadMessage once the connection is closed, with the code and reason it was closed with.
+// Connections lost without a close frame are reported with CloseAbnormal.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("barf: websocket closed with %d %s", e.Code, e.Reason)
+}
+
+// Conn is a WebSocket connection. Reads must come from a single goroutine while writes may come from several.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	// request is the request the connection was upgraded from
+	request *http.Request
+	// protocol is the negotiated subprotocol, if any
+	protocol string
+	// client masks written frames and expects unmasked ones, as the client end of a connection does
+	client bool
+	// max is the maximum number of bytes of a message
+	max int64
+	// idle is the time allowed between two frames before the peer is considered gone, zero for no limit
+	idle time.Duration
+	// timeout is the time allowed to write a frame, zero or negative for no limit
+	timeout time.Duration
+
+	// mu guards writes and closing
+	mu sync.Mutex
+	// sent is true once a close frame was written
+	sent bool
+	// err is the error reads fail with once done is closed
+	err  error
+	done chan struct{}
+	once sync.Once
+}
+
+// newConn wraps the given network connection. Messages are limited to MaxMessageSize unless a positive max is given.
+func newConn(conn net.Conn, reader *bufio.Reader, client bool, max int64) *Conn {
+	if max <= 0 {
+		max = MaxMessageSize
+	}
+	if reader == nil {
+		reader = bufio.NewReader(conn)
+	}
+	return &Conn{conn: conn, reader: reader, client: client, max: max, timeout: WriteTimeout, done: make(chan struct{})}
+}
+
+// Request returns the request the connection was upgraded from. Params, query and headers can be read from it as usual.
+func (c *Conn) Request() *http.Request {
+	return c.request
+}
+
+// Subprotocol returns the subprotocol negotiated during the handshake, if any
+func (c *Conn) Subprotocol() string {
+	return c.protocol
+}
+
+// Done returns a channel that is closed once the connection is closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+/*
+ReadMessage reads the next text or binary message. Pings are answered and close frames are echoed while reading,
+so a connection should be read from for as long as it is open, even when the messages are not needed.
+
+Once the connection is closed, ReadMessage returns a *ws.CloseError with the code and reason it was closed with.
+*/
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	select {
+	case <-c.done:
+		return 0, nil, c.err
+	default:
+	}
+	kind := continuation
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.frame(int64(len(message)))
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+		switch opcode {
+		case PingMessage:
+			if err := c.write(PongMessage, payload); err != nil {
+				return 0, nil, c.fail(err)
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			return 0, nil, c.closed(payload)
+		case continuation:
+			if kind == continuation {
+				return 0, nil, c.fail(&CloseError{CloseProtocolError, "unexpected continuation frame"})
+			}
+			message = append(message, payload...)
+		case TextMessage, BinaryMessage:
+			if kind != continuation {
+				return 0, nil, c.fail(&CloseError{CloseProtocolError, "expected a continuation frame"})
+			}
+			kind = opcode
+			message = payload
+		default:
+			return 0, nil, c.fail(&CloseError{CloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode)})
+		}
+		if !fin {
+			continue
+		}
+		if kind == TextMessage && !utf8.Valid(message) {
+			return 0, nil, c.fail(&CloseError{CloseInvalidPayload, "invalid UTF-8 in text message"})
+		}
+		return kind, message, nil
+	}
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v
+func (c *Conn) ReadJSON(v interface{}) error {
+	_, message, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(message, v)
+}
+
+// WriteMessage writes the given data as a single text or binary message
+func (c *Conn) WriteMessage(kind int, data []byte) error {
+	if kind != TextMessage && kind != BinaryMessage {
+		return fmt.Errorf("barf: invalid websocket message type %d", kind)
+	}
+	if kind == TextMessage && !utf8.Valid(data) {
+		return errors.New("barf: invalid UTF-8 in websocket text message")
+	}
+	return c.write(kind, data)
+}
+
+// WriteJSON writes v encoded as JSON in a text message
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.write(TextMessage, data)
+}
+
+// Ping writes a ping with the given data, of at most 125 bytes. The peer answers with a pong.
+func (c *Conn) Ping(data []byte) error {
+	if len(data) > 125 {
+		return errors.New("barf: websocket ping data is longer than 125 bytes")
+	}
+	return c.write(PingMessage, data)
+}
+
+// Close writes a close frame with the given code and reason, unless one was written already, and closes the connection
+func (c *Conn) Close(code int, reason string) error {
+	c.mu.Lock()
+	var err error
+	if !c.sent {
+		err = c.closeFrame(code, reason)
+	}
+	c.mu.Unlock()
+	c.shutdown(&CloseError{code, reason})
+	return err
+}
+
+// write writes a single frame holding the given payload
+func (c *Conn) write(opcode int, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sent {
+		return ErrClosed
+	}
+	return c.send(opcode, payload)
+}
+
+// send writes a frame. c.mu must be held.
+func (c *Conn) send(opcode int, payload []byte) error {
+	header := make([]byte, 2, 14)
+	header[0] = 0x80 | byte(opcode)
+	switch l := len(payload); {
+	case l <= 125:
+		header[1] = byte(l)
+	case l <= 0xffff:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(l))
+	default:
+		header[1] = 127
+		header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(l))
+	}
+	if c.client {
+		header[1] |= 0x80
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		header = append(header, key[:]...)
+		masked := make([]byte, len(payload))
+		copy(masked, payload)
+		mask(masked, key)
+		payload = masked
+	}
+	if c.timeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	}
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// closeFrame writes a close frame and stops further writes. c.mu must be held.
+func (c *Conn) closeFrame(code int, reason string) error {
+	c.sent = true
+	var payload []byte
+	if code != CloseNoStatus && code != CloseAbnormal {
+		payload = make([]byte, 2, 125)
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		if len(reason) > 123 {
+			// cut the reason at a rune boundary such that it stays valid UTF-8
+			n := 123
+			for n > 0 && !utf8.RuneStart(reason[n]) {
+				n--
+			}
+			reason = reason[:n]
+		}
+		payload = append(payload, reason...)
+	}
+	return c.send(CloseMessage, payload)
+}
+
+// frame reads the next frame. read is the number of bytes of the message read so far, which counts towards the size limit.
+func (c *Conn) frame(read int64) (bool, int, []byte, error) {
+	if c.idle > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.idle))
+	}
+	var head [2]byte
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	opcode := int(head[0] & 0x0f)
+	masked := head[1]&0x80 != 0
+	length := int64(head[1] & 0x7f)
+
+	// no extension is negotiated so the reserved bits must be unset
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, &CloseError{CloseProtocolError, "reserved bits are set"}
+	}
+	if masked == c.client {
+		return false, 0, nil, &CloseError{CloseProtocolError, "invalid frame masking"}
+	}
+	if opcode >= CloseMessage && (!fin || length > 125) {
+		return false, 0, nil, &CloseError{CloseProtocolError, "invalid control frame"}
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		if ext[0]&0x80 != 0 {
+			return false, 0, nil, &CloseError{CloseProtocolError, "invalid frame length"}
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if opcode < CloseMessage && read+length > c.max {
+		return false, 0, nil, &CloseError{CloseMessageTooBig, fmt.Sprintf("message is larger than %d bytes", c.max)}
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, key[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		mask(payload, key)
+	}
+	return fin, opcode, payload, nil
+}
+
+// closed handles a close frame received from the peer by echoing its code and closing the connection
+func (c *Conn) closed(payload []byte) error {
+	code, reason := CloseNoStatus, ""
+	switch {
+	case len(payload) == 1:
+		return c.fail(&CloseError{CloseProtocolError, "invalid close frame"})
+	case len(payload) >= 2:
+		code = int(binary.BigEndian.Uint16(payload))
+		reason = string(payload[2:])
+		if !valid(code) {
+			return c.fail(&CloseError{CloseProtocolError, fmt.Sprintf("invalid close code %d", code)})
+		}
+		if !utf8.ValidString(reason) {
+			return c.fail(&CloseError{CloseInvalidPayload, "invalid UTF-8 in close reason"})
+		}
+	}
+	c.mu.Lock()
+	if !c.sent {
+		echo := code
+		if echo == CloseNoStatus {
+			echo = CloseNormal
+		}
+		c.closeFrame(echo, "")
+	}
+	c.mu.Unlock()
+	return c.shutdown(&CloseError{code, reason})
+}
+
+// fail closes the connection because of the given read error. Protocol errors are sent to the peer first.
+func (c *Conn) fail(err error) error {
+	var ce *CloseError
+	if errors.As(err, &ce) {
+		c.mu.Lock()
+		if !c.sent {
+			c.closeFrame(ce.Code, ce.Reason)
+		}
+		c.mu.Unlock()
+		return c.shutdown(ce)
+	}
+	return c.shutdown(&CloseError{CloseAbnormal, err.Error()})
+}
+
+// shutdown closes the network connection and records the error reads fail with from now on
+func (c *Conn) shutdown(err *CloseError) error {
+	c.once.Do(func() {
+		c.err = err
+		c.conn.Close()
+		close(c.done)
+	})
+	return c.err
+}
+
+// keepalive pings the peer at the given interval until the connection is closed
+func (c *Conn) keepalive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if c.Ping(nil) != nil {
+				return
+			}
+		}
+	}
+}
+
+// valid reports whether the given close code may be received in a close frame
+func valid(code int) bool {
+	switch {
+	case code >= 3000 && code <= 4999:
+		return true
+	case code < 1000 || code > 1014:
+		return false
+	}
+	return code != 1004 && code != CloseNoStatus && code != CloseAbnormal
+}
+
+// mask masks or unmasks the given payload in place with the given key
+func mask(payload []byte, key [4]byte) {
+	for i := range payload {
+		payload[i] ^= key[i%4]
+	}
+}
@@ -0,0 +1,168 @@
+package ws
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// guid is appended to the key of the client to compute the accept key of the handshake
+const guid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// MaxMessageSize is the default maximum number of bytes of a received message
+const MaxMessageSize = 1 << 20
+
+// PingInterval is the default interval connections are pinged at
+const PingInterval = 30 * time.Second
+
+// WriteTimeout is the default time allowed to write a frame
+const WriteTimeout = 10 * time.Second
+
+// HandshakeError is returned by Upgrade for a request that is not a valid WebSocket handshake, along with the status it should be answered with
+type HandshakeError struct {
+	Status  int
+	Message string
+}
+
+func (e *HandshakeError) Error() string {
+	return e.Message
+}
+
+/*
+Upgrade completes the WebSocket handshake of the given request and takes over its connection. Nothing may be written to
+the response writer afterwards. Requests that are not a valid handshake are returned a *ws.HandshakeError and left untouched.
+*/
+func Upgrade(w http.ResponseWriter, r *http.Request, opts typing.WebSocket) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		return nil, &HandshakeError{http.StatusMethodNotAllowed, "WebSocket handshakes must use the GET method"}
+	}
+	if !token(r.Header, "Connection", "upgrade") || !token(r.Header, "Upgrade", "websocket") {
+		return nil, &HandshakeError{http.StatusBadRequest, "Request is not a WebSocket handshake"}
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, &HandshakeError{http.StatusUpgradeRequired, "Unsupported WebSocket version"}
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if raw, err := base64.StdEncoding.DecodeString(key); err != nil || len(raw) != 16 {
+		return nil, &HandshakeError{http.StatusBadRequest, "Invalid Sec-WebSocket-Key header"}
+	}
+	check := opts.CheckOrigin
+	if check == nil {
+		check = sameOrigin
+	}
+	if !check(r) {
+		return nil, &HandshakeError{http.StatusForbidden, fmt.Sprintf("WebSocket connections are not allowed from origin %s", r.Header.Get("Origin"))}
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, &HandshakeError{http.StatusInternalServerError, fmt.Sprintf("%T does not support hijacking", w)}
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, &HandshakeError{http.StatusInternalServerError, err.Error()}
+	}
+	// the deadlines of the server only apply to the request the connection was upgraded from
+	conn.SetDeadline(time.Time{})
+
+	c := newConn(conn, rw.Reader, false, opts.MaxMessageSize)
+	c.request = r
+	c.protocol = subprotocol(r, opts.Subprotocols)
+
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + accept(key) + "\r\n"
+	if c.protocol != "" {
+		response += "Sec-WebSocket-Protocol: " + c.protocol + "\r\n"
+	}
+	if _, err := conn.Write([]byte(response + "\r\n")); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if opts.WriteTimeout != 0 {
+		c.timeout = opts.WriteTimeout
+	}
+
+	interval := opts.PingInterval
+	if interval == 0 {
+		interval = PingInterval
+	}
+	if interval > 0 {
+		c.idle = 2 * interval
+		go c.keepalive(interval)
+	}
+	return c, nil
+}
+
+/*
+Handler returns a handler upgrading requests to WebSocket connections served by the given function. The connection is
+configured with the barf.Augment.WebSocket of the app serving the request and closed once the function returns.
+Requests that are not a valid handshake are answered with a JSON error.
+*/
+func Handler(serve func(*Conn)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var opts typing.WebSocket
+		if aug, ok := r.Context().Value(typing.AugmentCtxKey{}).(*typing.Augment); ok && aug.WebSocket != nil {
+			opts = *aug.WebSocket
+		}
+		c, err := Upgrade(w, r, opts)
+		if err != nil {
+			if he, ok := err.(*HandshakeError); ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(he.Status)
+				json.NewEncoder(w).Encode(typing.Response{Status: false, Message: he.Message})
+			}
+			return
+		}
+		defer c.Close(CloseNormal, "")
+		serve(c)
+	}
+}
+
+// accept returns the Sec-WebSocket-Accept value for the given Sec-WebSocket-Key
+func accept(key string) string {
+	sum := sha1.Sum([]byte(key + guid))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// token reports whether the comma separated values of the given header contain the given token, case insensitively
+func token(h http.Header, name, value string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subprotocol returns the most preferred of the supported subprotocols requested by the client, or "" if none is
+func subprotocol(r *http.Request, supported []string) string {
+	for _, s := range supported {
+		if token(r.Header, "Sec-WebSocket-Protocol", s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// sameOrigin reports whether the request has no Origin header or comes from the host it is sent to
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
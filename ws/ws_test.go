@@ -0,0 +1,274 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/opensaucerer/barf/typing"
+)
+
+// dial opens a client connection to the given test server with the given handshake headers
+func dial(t *testing.T, s *httptest.Server, path string, header http.Header) (*Conn, *http.Response) {
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := http.NewRequest(http.MethodGet, s.URL+path, nil)
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	if err := r.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, res
+	}
+	return newConn(conn, br, true, 0), res
+}
+
+// echo serves a connection by sending every message back
+func echo(c *Conn) {
+	for {
+		kind, message, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.WriteMessage(kind, message)
+	}
+}
+
+// go test -v -run TestWebSocketUnit ./...
+func TestWebSocketUnit(t *testing.T) {
+
+	closed := make(chan error, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r, typing.WebSocket{MaxMessageSize: 1 << 17, Subprotocols: []string{"zeina.v2", "zeina.v1"}})
+		if err != nil {
+			he := err.(*HandshakeError)
+			w.WriteHeader(he.Status)
+			return
+		}
+		defer c.Close(CloseNormal, "")
+		echo(c)
+		if r.URL.Path == "/v1/stream/close" {
+			_, _, err = c.ReadMessage()
+			closed <- err
+		}
+	}))
+	defer s.Close()
+
+	t.Run("Should compute the accept key", func(t *testing.T) {
+
+		if got := accept("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+			t.Fatalf("unexpected accept key: got %v want %v", got, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=")
+		}
+
+	})
+
+	t.Run("Should echo text and binary messages", func(t *testing.T) {
+
+		c, res := dial(t, s, "/v1/stream", http.Header{"Sec-Websocket-Protocol": {"zeina.v1, zeina.v2"}})
+		if c == nil {
+			t.Fatalf("unexpected status: got %v", res.StatusCode)
+		}
+		defer c.Close(CloseNormal, "")
+		if res.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" || res.Header.Get("Sec-WebSocket-Protocol") != "zeina.v2" {
+			t.Fatalf("unexpected handshake: got %v", res.Header)
+		}
+
+		for _, m := range []struct {
+			kind int
+			data []byte
+		}{
+			{TextMessage, []byte("deposit processed")},
+			{BinaryMessage, bytes.Repeat([]byte{7}, 300)},
+			{TextMessage, bytes.Repeat([]byte("a"), 70000)},
+		} {
+			if err := c.WriteMessage(m.kind, m.data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			kind, data, err := c.ReadMessage()
+			if err != nil || kind != m.kind || !bytes.Equal(data, m.data) {
+				t.Fatalf("unexpected message: got %v %v %v", kind, len(data), err)
+			}
+		}
+
+	})
+
+	t.Run("Should join fragmented messages and answer pings in between", func(t *testing.T) {
+
+		c, _ := dial(t, s, "/v1/stream", nil)
+		defer c.Close(CloseNormal, "")
+
+		// a text message sent in two frames with a ping in between
+		c.mu.Lock()
+		c.conn.Write(frame(TextMessage, false, "with"))
+		c.mu.Unlock()
+		c.Ping([]byte("teller"))
+		c.mu.Lock()
+		c.conn.Write(frame(continuation, true, "draw"))
+		c.mu.Unlock()
+
+		fin, opcode, payload, err := c.frame(0)
+		if err != nil || !fin || opcode != PongMessage || string(payload) != "teller" {
+			t.Fatalf("unexpected frame: got %v %v %q %v", fin, opcode, payload, err)
+		}
+		kind, data, err := c.ReadMessage()
+		if err != nil || kind != TextMessage || string(data) != "withdraw" {
+			t.Fatalf("unexpected message: got %v %q %v", kind, data, err)
+		}
+
+	})
+
+	t.Run("Should close the connection with the code of the client", func(t *testing.T) {
+
+		c, _ := dial(t, s, "/v1/stream/close", nil)
+		c.Close(CloseGoingAway, "bye")
+
+		var ce *CloseError
+		if err := <-closed; !errors.As(err, &ce) || ce.Code != CloseGoingAway || ce.Reason != "bye" {
+			t.Fatalf("unexpected error: got %v", err)
+		}
+
+	})
+
+	cases := []struct {
+		name  string
+		frame func(c *Conn)
+		code  int
+	}{
+		{"Should close connections sending messages over the size limit", func(c *Conn) {
+			c.WriteMessage(BinaryMessage, make([]byte, 1<<17+1))
+		}, CloseMessageTooBig},
+		{"Should close connections sending unmasked frames", func(c *Conn) {
+			c.client = false
+			c.WriteMessage(TextMessage, []byte("unmasked"))
+		}, CloseProtocolError},
+		{"Should close connections sending invalid UTF-8", func(c *Conn) {
+			c.write(TextMessage, []byte{0xff, 0xfe})
+		}, CloseInvalidPayload},
+		{"Should close connections sending unexpected continuation frames", func(c *Conn) {
+			c.write(continuation, []byte("draw"))
+		}, CloseProtocolError},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+
+			c, _ := dial(t, s, "/v1/stream", nil)
+			defer c.Close(CloseNormal, "")
+			cs.frame(c)
+
+			c.client = true
+			var ce *CloseError
+			if _, _, err := c.ReadMessage(); !errors.As(err, &ce) || ce.Code != cs.code {
+				t.Fatalf("unexpected error: got %v want %v", err, cs.code)
+			}
+
+		})
+	}
+
+	t.Run("Should keep the default size limit for non-positive limits", func(t *testing.T) {
+
+		server, client := net.Pipe()
+		defer server.Close()
+		defer client.Close()
+		c := newConn(server, nil, false, -1)
+
+		// a masked binary frame declaring a payload of 1 TB
+		go client.Write([]byte{0x80 | BinaryMessage, 0x80 | 127, 0, 0, 1, 0, 0, 0, 0, 0})
+
+		var ce *CloseError
+		if _, _, _, err := c.frame(0); !errors.As(err, &ce) || ce.Code != CloseMessageTooBig {
+			t.Fatalf("unexpected error: got %v want %v", err, CloseMessageTooBig)
+		}
+
+	})
+
+	t.Run("Should cut long close reasons at a rune boundary", func(t *testing.T) {
+
+		server, client := net.Pipe()
+		defer client.Close()
+		c := newConn(server, nil, false, 0)
+		peer := newConn(client, nil, true, 0)
+
+		go c.Close(CloseGoingAway, strings.Repeat("é", 62))
+
+		_, opcode, payload, err := peer.frame(0)
+		if err != nil || opcode != CloseMessage {
+			t.Fatalf("unexpected frame: got %v %v want %v", opcode, err, CloseMessage)
+		}
+		if reason := payload[2:]; len(reason) != 122 || !utf8.Valid(reason) {
+			t.Fatalf("unexpected reason: got %d bytes valid %v want %d bytes valid %v", len(reason), utf8.Valid(reason), 122, true)
+		}
+
+	})
+
+	t.Run("Should fail writes to peers that stop reading", func(t *testing.T) {
+
+		server, client := net.Pipe()
+		defer server.Close()
+		defer client.Close()
+		c := newConn(server, nil, false, 0)
+		c.timeout = 50 * time.Millisecond
+
+		var ne net.Error
+		if err := c.WriteMessage(TextMessage, []byte("balance: 2500")); !errors.As(err, &ne) || !ne.Timeout() {
+			t.Fatalf("unexpected error: got %v want a timeout", err)
+		}
+
+	})
+
+	t.Run("Should reject invalid handshakes", func(t *testing.T) {
+
+		for _, h := range []struct {
+			header http.Header
+			status int
+		}{
+			{http.Header{"Upgrade": {"h2c"}}, http.StatusBadRequest},
+			{http.Header{"Sec-Websocket-Version": {"8"}}, http.StatusUpgradeRequired},
+			{http.Header{"Sec-Websocket-Key": {"short"}}, http.StatusBadRequest},
+			{http.Header{"Origin": {"https://evil.example"}}, http.StatusForbidden},
+		} {
+			if c, res := dial(t, s, "/v1/stream", h.header); c != nil || res.StatusCode != h.status {
+				t.Fatalf("unexpected status for %v: got %v want %v", h.header, res.StatusCode, h.status)
+			}
+		}
+
+	})
+}
+
+// frame builds a masked client frame with a short payload
+func frame(opcode int, fin bool, payload string) []byte {
+	var b bytes.Buffer
+	head := byte(opcode)
+	if fin {
+		head |= 0x80
+	}
+	b.WriteByte(head)
+	b.WriteByte(0x80 | byte(len(payload)))
+	key := [4]byte{1, 2, 3, 4}
+	b.Write(key[:])
+	masked := []byte(payload)
+	mask(masked, key)
+	b.Write(masked)
+	return b.Bytes()
+}